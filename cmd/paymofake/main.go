@@ -0,0 +1,32 @@
+// Command paymofake serves demo data over a fake Paymo API, so paymostats can
+// be tried without a Paymo account:
+//
+//	go run ./cmd/paymofake -addr localhost:8080
+//	PAYMOSTATS_API_KEY=demo paymostats --api-url http://localhost:8080 --range 3m
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api/paymotest"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "listen address")
+	rateLimit := flag.Int("rate-limit", 0, "answer the first N requests with 429")
+	member := flag.Bool("member", false, "act like a non-admin key: no access to other users' data")
+	flag.Parse()
+
+	fake := &paymotest.Fake{
+		Fixtures:   paymotest.Demo(time.Now().UTC()),
+		RateLimit:  *rateLimit,
+		RetryAfter: time.Second,
+		Member:     *member,
+	}
+
+	log.Printf("fake Paymo API listening on http://%s (any API key is accepted)", *addr)
+	log.Fatal(http.ListenAndServe(*addr, fake))
+}
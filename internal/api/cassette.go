@@ -0,0 +1,184 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Cassette is a recorded set of HTTP interactions with the Paymo API, stored
+// as JSON. Credentials are stripped before anything is written
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+}
+
+type RecordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+}
+
+// Headers that must never end up in a cassette
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+func sanitize(h http.Header) http.Header {
+	h = h.Clone()
+	for _, k := range sensitiveHeaders {
+		h.Del(k)
+	}
+	return h
+}
+
+// relativeURL strips the client's base URL, so a cassette recorded against
+// one base URL (say a local mock) replays against any other
+func relativeURL(base string, req *http.Request) string {
+	return strings.TrimPrefix(req.URL.String(), base)
+}
+
+// LoadCassette reads a cassette written by a recording client
+func LoadCassette(path string) (*Cassette, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("parse cassette %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// WithRecording writes every request/response pair to a cassette at path,
+// rewriting the file after each interaction so an aborted run keeps what it got
+func WithRecording(path string) Option {
+	return func(o *clientOptions) { o.recordTo = path }
+}
+
+// WithReplay serves responses from the cassette instead of the network.
+// Identical requests get the recorded responses in order, the last one repeating
+func WithReplay(c *Cassette) Option {
+	return func(o *clientOptions) { o.replay = c }
+}
+
+type recordTransport struct {
+	rt   http.RoundTripper
+	base string
+	path string
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.rt.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cassette.Interactions = append(t.cassette.Interactions, Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    relativeURL(t.base, req),
+			Header: sanitize(req.Header),
+		},
+		Response: RecordedResponse{
+			Status: resp.StatusCode,
+			Header: sanitize(resp.Header),
+			Body:   string(body),
+		},
+	})
+	if err := t.save(); err != nil {
+		return nil, fmt.Errorf("record cassette: %w", err)
+	}
+	return resp, nil
+}
+
+// save writes the cassette atomically (temp file + rename)
+func (t *recordTransport) save() error {
+	b, err := json.MarshalIndent(t.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(t.path), ".cassette-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), t.path)
+}
+
+type replayTransport struct {
+	base string
+
+	mu     sync.Mutex
+	queues map[string][]RecordedResponse
+}
+
+func newReplayTransport(base string, c *Cassette) *replayTransport {
+	t := &replayTransport{base: base, queues: make(map[string][]RecordedResponse)}
+	for _, in := range c.Interactions {
+		k := in.Request.Method + " " + in.Request.URL
+		t.queues[k] = append(t.queues[k], in.Response)
+	}
+	return t
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	k := req.Method + " " + relativeURL(t.base, req)
+
+	t.mu.Lock()
+	q := t.queues[k]
+	if len(q) == 0 {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("no recorded response for %s (rolling ranges change with the clock, replay with fixed --start/--end)", k)
+	}
+	rec := q[0]
+	if len(q) > 1 {
+		t.queues[k] = q[1:]
+	}
+	t.mu.Unlock()
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rec.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader([]byte(rec.Body))),
+		ContentLength: int64(len(rec.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// Upper bound on concurrent /entries requests for one Entries call
+	chunkWorkers = 4
+	// Upper bound on chunks per call; longer ranges get multi-month chunks
+	// so "All time" doesn't turn into hundreds of requests
+	maxChunks = 48
+)
+
+type chunk struct {
+	start, end time.Time
+}
+
+// splitRange cuts [start, end] into consecutive, non-overlapping windows.
+// Windows are a week long for ranges up to a month, otherwise whole months
+// (or multiples of a month, capped at maxChunks)
+func splitRange(start, end time.Time) []chunk {
+	start, end = start.UTC(), end.UTC()
+	if !end.After(start) {
+		return []chunk{{start, end}}
+	}
+
+	step := func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	if end.After(start.AddDate(0, 1, 0)) {
+		months := (end.Year()-start.Year())*12 + int(end.Month()-start.Month()) + 1
+		perChunk := (months + maxChunks - 1) / maxChunks
+		step = func(t time.Time) time.Time { return t.AddDate(0, perChunk, 0) }
+	}
+
+	var chunks []chunk
+	for from := start; from.Before(end); {
+		to := step(from)
+		if to.After(end) {
+			to = end
+		}
+		chunks = append(chunks, chunk{from, to})
+		from = to
+	}
+	return chunks
+}
+
+// entriesChunked fetches every chunk with a bounded worker pool, drops
+// entries that straddle a boundary and came back twice, and returns them in
+// chronological order. The first failing chunk (in range order) is reported
+func (c *Client) entriesChunked(ctx context.Context, userID int, chunks []chunk, f EntryFilter) ([]TimeEntry, error) {
+	results := make([][]TimeEntry, len(chunks))
+	errs := make([]error, len(chunks))
+
+	jobs := make(chan int)
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	for range min(chunkWorkers, len(chunks)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				// Once a chunk failed the result is discarded anyway
+				if failed.Load() || ctx.Err() != nil {
+					continue
+				}
+				results[i], errs[i] = c.entriesWindow(ctx, userID, chunks[i].start, chunks[i].end, f)
+				if errs[i] != nil {
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	for i := range chunks {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var all []TimeEntry
+	seen := make(map[int]bool)
+	for i, entries := range results {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("entries %s to %s: %w",
+				chunks[i].start.Format("2006-01-02"), chunks[i].end.Format("2006-01-02"), errs[i])
+		}
+		for _, e := range entries {
+			if e.ID != 0 {
+				if seen[e.ID] {
+					continue
+				}
+				seen[e.ID] = true
+			}
+			all = append(all, e)
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		ti, tj := all[i].Time(), all[j].Time()
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return all[i].ID < all[j].ID
+	})
+	return all, nil
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api/paymotest"
+)
+
+func TestSplitRange(t *testing.T) {
+	day := func(s string) time.Time {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	tests := []struct {
+		name       string
+		start, end time.Time
+		wantChunks int
+		wantFirst  time.Time // end of the first chunk
+	}{
+		{name: "empty range", start: day("2026-03-02"), end: day("2026-03-02"), wantChunks: 1, wantFirst: day("2026-03-02")},
+		{name: "end before start", start: day("2026-03-02"), end: day("2026-03-01"), wantChunks: 1, wantFirst: day("2026-03-01")},
+		{name: "within a week", start: day("2026-03-02"), end: day("2026-03-05"), wantChunks: 1, wantFirst: day("2026-03-05")},
+		{name: "ten days in weeks", start: day("2026-03-02"), end: day("2026-03-12"), wantChunks: 2, wantFirst: day("2026-03-09")},
+		{name: "a whole month in weeks", start: day("2026-03-01"), end: day("2026-04-01"), wantChunks: 5, wantFirst: day("2026-03-08")},
+		{name: "past a month in months", start: day("2026-03-01"), end: day("2026-05-15"), wantChunks: 3, wantFirst: day("2026-04-01")},
+		{name: "four years in months", start: day("2022-01-01"), end: day("2025-12-31"), wantChunks: 48, wantFirst: day("2022-02-01")},
+		{name: "ten years widen the step", start: day("2016-01-01"), end: day("2025-12-31"), wantChunks: 40, wantFirst: day("2016-04-01")},
+		{name: "all time stays capped", start: time.Unix(0, 0), end: day("2026-03-20"), wantChunks: 45, wantFirst: day("1971-04-01")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := splitRange(tt.start, tt.end)
+			if len(chunks) != tt.wantChunks {
+				t.Errorf("got %d chunks, want %d", len(chunks), tt.wantChunks)
+			}
+			if len(chunks) > maxChunks {
+				t.Errorf("got %d chunks, more than the cap of %d", len(chunks), maxChunks)
+			}
+			if !chunks[0].start.Equal(tt.start.UTC()) || !chunks[len(chunks)-1].end.Equal(tt.end.UTC()) {
+				t.Errorf("chunks cover %s to %s, want %s to %s", chunks[0].start, chunks[len(chunks)-1].end, tt.start, tt.end)
+			}
+			if !chunks[0].end.Equal(tt.wantFirst) {
+				t.Errorf("first chunk ends %s, want %s", chunks[0].end, tt.wantFirst)
+			}
+			for i := 1; i < len(chunks); i++ {
+				if !chunks[i].start.Equal(chunks[i-1].end) {
+					t.Errorf("chunk %d starts %s, the one before ends %s", i, chunks[i].start, chunks[i-1].end)
+				}
+			}
+		})
+	}
+}
+
+// boundaryFixtures has an entry a day from January to mid March 2026, plus
+// entries on the month boundaries that every adjacent chunk returns
+func boundaryFixtures() paymotest.Fixtures {
+	f := paymotest.Fixtures{UserID: 1}
+	add := func(start time.Time, d time.Duration) {
+		f.Entries = append(f.Entries, paymotest.Entry{ID: len(f.Entries) + 1, UserID: 1, ProjectID: 101, Start: start, Duration: d})
+	}
+	for day := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC); day.Month() < 3 || day.Day() < 15; day = day.AddDate(0, 0, 1) {
+		add(day, time.Hour)
+	}
+	add(time.Date(2026, 1, 31, 23, 30, 0, 0, time.UTC), time.Hour) // straddles Feb 1
+	add(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Hour)    // starts on the boundary
+	return f
+}
+
+var (
+	chunkedStart = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	chunkedEnd   = time.Date(2026, 3, 14, 23, 59, 59, 0, time.UTC)
+)
+
+func TestEntriesChunked(t *testing.T) {
+	fixtures := boundaryFixtures()
+	fake := &paymotest.Fake{Fixtures: fixtures}
+	srv := paymotest.NewServer(fake)
+	defer srv.Close()
+
+	got, err := testClient(srv.URL, RetryPolicy{MaxAttempts: 1}).Entries(context.Background(), 1, chunkedStart, chunkedEnd)
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if n, want := fake.Requests(), len(splitRange(chunkedStart, chunkedEnd)); n != want {
+		t.Errorf("made %d requests, want one per chunk (%d)", n, want)
+	}
+	if len(got) != len(fixtures.Entries) {
+		t.Errorf("got %d entries, want %d", len(got), len(fixtures.Entries))
+	}
+	seen := make(map[int]bool)
+	for i, e := range got {
+		if seen[e.ID] {
+			t.Errorf("entry %d returned twice", e.ID)
+		}
+		seen[e.ID] = true
+		if i > 0 && e.Time().Before(got[i-1].Time()) {
+			t.Errorf("entry %d at %s comes after %s", e.ID, e.Time(), got[i-1].Time())
+		}
+	}
+}
+
+// TestEntriesChunkedErrors checks the earliest failing chunk is the one
+// reported, however the workers finish
+func TestEntriesChunkedErrors(t *testing.T) {
+	chunks := splitRange(chunkedStart, chunkedEnd)
+	if len(chunks) < 3 {
+		t.Fatalf("only %d chunks, the test needs 3", len(chunks))
+	}
+	tests := []struct {
+		name    string
+		failing []int // chunk indexes, in the order they fail
+		want    int   // the chunk the error names
+	}{
+		{"first", []int{0}, 0},
+		{"last", []int{len(chunks) - 1}, len(chunks) - 1},
+		{"earliest of two", []int{2, 1}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &paymotest.Fake{Fixtures: boundaryFixtures()}
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				where := r.URL.Query().Get("where")
+				for i, c := range tt.failing {
+					if strings.Contains(where, `("`+chunks[c].start.Format("2006-01-02T15:04:05Z")) {
+						// Failures listed first answer first, so the later chunk
+						// can fail before the earlier one
+						time.Sleep(time.Duration(i) * 50 * time.Millisecond)
+						http.Error(w, `{"message":"Internal error"}`, http.StatusInternalServerError)
+						return
+					}
+				}
+				fake.ServeHTTP(w, r)
+			}))
+			defer srv.Close()
+
+			_, err := testClient(srv.URL, RetryPolicy{MaxAttempts: 1}).Entries(context.Background(), 1, chunkedStart, chunkedEnd)
+			if err == nil {
+				t.Fatal("no error")
+			}
+			c := chunks[tt.want]
+			want := fmt.Sprintf("entries %s to %s", c.start.Format("2006-01-02"), c.end.Format("2006-01-02"))
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error %q, want it to name %q", err, want)
+			}
+		})
+	}
+}
@@ -1,27 +1,109 @@
 package api
 
 import (
+	"io"
 	"log"
+	"log/slog"
 	"net/http"
-	"net/http/httputil"
+	"time"
 )
 
-type debugTransport struct {
-	rt http.RoundTripper
+// LevelTrace is below slog.LevelDebug: besides the per-request summary
+// logged at debug, trace also logs (redacted) headers and response bodies
+const LevelTrace = slog.LevelDebug - 4
+
+// WithLogger traces every HTTP attempt (method, redacted URL, status, timing,
+// byte counts) and every retry to l. Credentials are never logged
+func WithLogger(l *slog.Logger) Option {
+	return func(o *clientOptions) { o.logger = l }
+}
+
+// EnableDebug traces every request and response at LevelTrace to the
+// standard logger's output, keeping the configured timeout and proxy.
+//
+// Deprecated: use WithLogger, which also takes the level and handler.
+func (c *Client) EnableDebug() {
+	l := slog.New(slog.NewTextHandler(log.Writer(), &slog.HandlerOptions{Level: LevelTrace}))
+	rt := c.http.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	hc := *c.http
+	hc.Transport = traceTransport{rt: rt, log: l}
+	c.http = &hc
+	c.log = l
 }
 
-func (t debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	dumpReq, _ := httputil.DumpRequestOut(req, true)
-	log.Printf("REQUEST:\n%s\n", dumpReq)
+type traceTransport struct {
+	rt  http.RoundTripper
+	log *slog.Logger
+}
+
+func (t traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	attrs := []any{"method", req.Method, "url", req.URL.Redacted()}
+	t.log.Log(ctx, LevelTrace, "http request headers", append(attrs, "header", redactHeader(req.Header))...)
+
+	start := time.Now()
 	resp, err := t.rt.RoundTrip(req)
 	if err != nil {
+		t.log.DebugContext(ctx, "http request failed", append(attrs, "duration", time.Since(start), "error", err)...)
 		return resp, err
 	}
-	dumpResp, _ := httputil.DumpResponse(resp, true)
-	log.Printf("RESPONSE:\n%s\n", dumpResp)
+
+	attrs = append(attrs, "status", resp.StatusCode)
+	t.log.Log(ctx, LevelTrace, "http response headers", append(attrs, "header", redactHeader(resp.Header))...)
+
+	// The summary is logged once the caller is done with the body, so the
+	// timing and byte count cover the whole transfer
+	resp.Body = &tracedBody{
+		ReadCloser: resp.Body,
+		done: func(n int64, body []byte) {
+			t.log.DebugContext(ctx, "http request", append(attrs, "duration", time.Since(start), "bytes", n)...)
+			if body != nil {
+				t.log.Log(ctx, LevelTrace, "http response body", append(attrs, "body", string(body))...)
+			}
+		},
+		keep: t.log.Enabled(ctx, LevelTrace),
+	}
 	return resp, nil
 }
 
-func (c *Client) EnableDebug() {
-	c.http = &http.Client{Transport: debugTransport{rt: http.DefaultTransport}}
+func redactHeader(h http.Header) http.Header {
+	h = h.Clone()
+	for _, k := range sensitiveHeaders {
+		if h.Get(k) != "" {
+			h.Set(k, "REDACTED")
+		}
+	}
+	return h
+}
+
+// tracedBody counts (and at trace level keeps) what is read through it and
+// reports it once on Close
+type tracedBody struct {
+	io.ReadCloser
+	done func(n int64, body []byte)
+	keep bool
+
+	n      int64
+	buf    []byte
+	closed bool
+}
+
+func (b *tracedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	if b.keep {
+		b.buf = append(b.buf, p[:n]...)
+	}
+	return n, err
+}
+
+func (b *tracedBody) Close() error {
+	if !b.closed {
+		b.closed = true
+		b.done(b.n, b.buf)
+	}
+	return b.ReadCloser.Close()
 }
@@ -4,5 +4,7 @@ import "errors"
 
 var (
 	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("permission denied")
 	ErrLoginAborted = errors.New("login aborted")
+	ErrRateLimited  = errors.New("rate limited by Paymo")
 )
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxFilterIDs caps the IDs sent in one "in" clause, so a broad filter can't
+// blow up the request URL. Longer lists aren't sent; callers filter locally
+const maxFilterIDs = 100
+
+// EntryFilter narrows down the entries Paymo sends, see FilteredEntries.
+// Empty fields don't filter. Paymo may apply it only partly (see where), so
+// callers still check the entries they get back
+type EntryFilter struct {
+	ProjectIDs  []int
+	TaskIDs     []int
+	Description string // text the description contains, case insensitive
+}
+
+// where returns the filter as clauses to append to an entries where clause
+func (f EntryFilter) where() string {
+	var b strings.Builder
+	if len(f.ProjectIDs) > 0 && len(f.ProjectIDs) <= maxFilterIDs {
+		fmt.Fprintf(&b, " and project_id in (%s)", joinIDs(f.ProjectIDs))
+	}
+	if len(f.TaskIDs) > 0 && len(f.TaskIDs) <= maxFilterIDs {
+		fmt.Fprintf(&b, " and task_id in (%s)", joinIDs(f.TaskIDs))
+	}
+	// Paymo has no escaping in where values, so text that can't be quoted
+	// safely (or would act as a wildcard) is left to the caller
+	if f.Description != "" && !strings.ContainsAny(f.Description, `"\%_`) {
+		fmt.Fprintf(&b, ` and description like "%%%s%%"`, f.Description)
+	}
+	return b.String()
+}
+
+// And combines two filters an entry must both pass: ID lists are
+// intersected (nil lists any ID) and the first description is kept, as
+// callers check entries themselves anyway
+func (f EntryFilter) And(g EntryFilter) EntryFilter {
+	f.ProjectIDs = intersect(f.ProjectIDs, g.ProjectIDs)
+	f.TaskIDs = intersect(f.TaskIDs, g.TaskIDs)
+	if f.Description == "" {
+		f.Description = g.Description
+	}
+	return f
+}
+
+func intersect(a, b []int) []int {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	out := []int{}
+	for _, id := range a {
+		if slices.Contains(b, id) {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func joinIDs(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, ",")
+}
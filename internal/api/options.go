@@ -0,0 +1,123 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBaseURL is the production Paymo API root
+	DefaultBaseURL = "https://app.paymoapp.com/api"
+
+	defaultTimeout   = 30 * time.Second
+	defaultUserAgent = "paymostats"
+)
+
+// Option configures a Client in NewClient
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	baseURL    string
+	httpClient *http.Client
+	timeout    *time.Duration
+	userAgent  string
+	proxy      *url.URL
+	retry      RetryPolicy
+	recordTo   string
+	replay     *Cassette
+	logger     *slog.Logger
+}
+
+// WithBaseURL points the client at a different API root, e.g. a local mock
+// server. Empty keeps the default
+func WithBaseURL(u string) Option {
+	return func(o *clientOptions) {
+		if u != "" {
+			o.baseURL = u
+		}
+	}
+}
+
+// WithHTTPClient uses hc instead of a fresh client. It is copied, so the
+// timeout and proxy options never modify the caller's client
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *clientOptions) { o.httpClient = hc }
+}
+
+// WithTimeout bounds every single HTTP attempt; 0 disables the timeout.
+// Defaults to 30s unless a client passed via WithHTTPClient brings its own
+func WithTimeout(d time.Duration) Option {
+	return func(o *clientOptions) { o.timeout = &d }
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(ua string) Option {
+	return func(o *clientOptions) {
+		if ua != "" {
+			o.userAgent = ua
+		}
+	}
+}
+
+// WithProxy routes all requests through the given proxy. Without it the
+// standard HTTP(S)_PROXY environment variables apply. Ignored for clients
+// passed via WithHTTPClient whose transport isn't an *http.Transport
+func WithProxy(proxyURL *url.URL) Option {
+	return func(o *clientOptions) { o.proxy = proxyURL }
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(o *clientOptions) { o.retry = p }
+}
+
+// base returns the normalized base URL the client will use
+func (o clientOptions) base() string {
+	return strings.TrimRight(o.baseURL, "/")
+}
+
+// build assembles the *http.Client described by the options
+func (o clientOptions) build() *http.Client {
+	hc := &http.Client{Timeout: defaultTimeout}
+	if o.httpClient != nil {
+		cp := *o.httpClient
+		hc = &cp
+	}
+	if o.timeout != nil {
+		hc.Timeout = *o.timeout
+	}
+
+	if o.replay != nil {
+		hc.Transport = newReplayTransport(o.base(), o.replay)
+	} else if o.proxy != nil {
+		base := hc.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		if t, ok := base.(*http.Transport); ok {
+			t = t.Clone()
+			t.Proxy = http.ProxyURL(o.proxy)
+			hc.Transport = t
+		}
+	}
+
+	if o.recordTo != "" {
+		base := hc.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		hc.Transport = &recordTransport{rt: base, base: o.base(), path: o.recordTo}
+	}
+
+	if o.logger != nil {
+		base := hc.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		hc.Transport = traceTransport{rt: base, log: o.logger}
+	}
+	return hc
+}
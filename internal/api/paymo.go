@@ -1,11 +1,16 @@
 package api
 
 import (
+	"cmp"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"reflect"
 	"strconv"
 	"time"
 )
@@ -62,43 +67,134 @@ func (u *UnixTS) UnmarshalJSON(b []byte) error {
 }
 
 type Client struct {
-	apiKey string
-	http   *http.Client
+	apiKey    string
+	baseURL   string
+	userAgent string
+	http      *http.Client
+	retry     RetryPolicy
+	log       *slog.Logger
+	pageSize  int // entries per page, entriesPageSize but for tests
 }
 
-func NewClient(apiKey string) *Client {
+// NewClient creates a Paymo client for the given API key. Without options it
+// talks to DefaultBaseURL with a 30s timeout and DefaultRetryPolicy
+func NewClient(apiKey string, opts ...Option) *Client {
+	o := clientOptions{
+		baseURL:   DefaultBaseURL,
+		userAgent: defaultUserAgent,
+		retry:     DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
 	return &Client{
-		apiKey: apiKey,
-		http:   http.DefaultClient,
+		apiKey:    apiKey,
+		baseURL:   o.base(),
+		userAgent: o.userAgent,
+		http:      o.build(),
+		retry:     o.retry,
+		log:       cmp.Or(o.logger, slog.New(slog.DiscardHandler)),
+		pageSize:  entriesPageSize,
 	}
 }
 
+// Return the absolute URL for an API path like "/me"
+func (c *Client) endpoint(path string) string {
+	return c.baseURL + path
+}
+
 type User struct {
-	ID int `json:"id"`
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	// PricePerHour is the user's rate, the fallback when neither the entry,
+	// task nor project has one. nil if not set
+	PricePerHour *float64 `json:"price_per_hour,omitempty"`
 }
 
 type TimeEntry struct {
+	ID        int     `json:"id"`
 	ProjectID int     `json:"project_id"`
+	TaskID    int     `json:"task_id"`
+	UserID    int     `json:"user_id"`
 	Duration  float64 `json:"duration"` // seconds
 
+	// Billable overrides the task/project setting when Paymo sends it
+	Billable *bool    `json:"billable,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	// PricePerHour overrides every other rate when Paymo sends it
+	PricePerHour *float64 `json:"price_per_hour,omitempty"`
+	Description  string   `json:"description,omitempty"`
+
 	// Paymo may send these as numbers or as strings – handle both with UnixTS
 	StartTime *UnixTS `json:"start_time,omitempty"`
 	Date      *UnixTS `json:"date,omitempty"`
 }
 
+// Time returns when the entry happened: its start time for timer entries,
+// or its date for manually added ones. Zero if Paymo sent neither
+func (e TimeEntry) Time() time.Time {
+	switch {
+	case e.StartTime != nil:
+		return time.Unix(int64(*e.StartTime), 0).UTC()
+	case e.Date != nil:
+		return time.Unix(int64(*e.Date), 0).UTC()
+	default:
+		return time.Time{}
+	}
+}
+
 type Project struct {
+	ID           int      `json:"id"`
+	Name         string   `json:"name"`
+	ClientID     int      `json:"client_id"` // 0 if the project has no client
+	Billable     bool     `json:"billable"`
+	PricePerHour *float64 `json:"price_per_hour,omitempty"` // nil if not set
+	// Currency of the project's amounts (ISO code, e.g. "EUR"); empty for
+	// the company default
+	Currency string `json:"currency,omitempty"`
+	// BudgetHours is the project's time budget; nil if it has none
+	BudgetHours *float64 `json:"budget_hours,omitempty"`
+}
+
+type Task struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	ProjectID  int    `json:"project_id"`
+	TaskListID int    `json:"tasklist_id"`
+	Billable   *bool  `json:"billable,omitempty"` // nil = inherit from project
+	// PricePerHour overrides the project and user rates; nil if not set
+	PricePerHour *float64 `json:"price_per_hour,omitempty"`
+}
+
+type TaskList struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	ProjectID int    `json:"project_id"`
+}
+
+// Customer is a Paymo client (named so it doesn't clash with the API Client)
+type Customer struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
 }
 
 // Return the current user id
-func (c *Client) Me() (int, error) {
-	req, _ := http.NewRequest("GET", "https://app.paymoapp.com/api/me", nil)
+func (c *Client) Me(ctx context.Context) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpoint("/me"), nil)
+	if err != nil {
+		return 0, err
+	}
 
 	var out struct {
 		Users []User `json:"users"`
 	}
 	if err := c.do(req, &out); err != nil {
+		// Any valid key may read /me, so a 403 means Paymo no longer
+		// accepts the key, e.g. because it was revoked
+		if errors.Is(err, ErrForbidden) {
+			return 0, fmt.Errorf("%w: %v", ErrUnauthorized, err)
+		}
 		return 0, err
 	}
 	if len(out.Users) == 0 {
@@ -107,21 +203,95 @@ func (c *Client) Me() (int, error) {
 	return out.Users[0].ID, nil
 }
 
-// Fetch time entries for a user within [start, end] using time_interval
-func (c *Client) Entries(userID int, start, end time.Time) ([]TimeEntry, error) {
-	u, _ := url.Parse("https://app.paymoapp.com/api/entries")
+// Number of entries requested per page when walking /entries. Paymo serves
+// pages this large, so a shorter page is the last one
+const entriesPageSize = 500
 
+// Fetch time entries for a user within [start, end] using time_interval.
+// Long windows are split into chunks and fetched concurrently (see chunks.go)
+func (c *Client) Entries(ctx context.Context, userID int, start, end time.Time) ([]TimeEntry, error) {
+	return c.entriesChunked(ctx, userID, splitRange(start, end), EntryFilter{})
+}
+
+// FilteredEntries is Entries with the filter added to the where clause, so
+// Paymo only sends matching entries
+func (c *Client) FilteredEntries(ctx context.Context, userID int, start, end time.Time, f EntryFilter) ([]TimeEntry, error) {
+	return c.entriesChunked(ctx, userID, splitRange(start, end), f)
+}
+
+// Fetch all entries for a single window, walking Paymo's page/page_size paging
+// until a page comes back shorter than requested
+func (c *Client) entriesWindow(ctx context.Context, userID int, start, end time.Time, f EntryFilter) ([]TimeEntry, error) {
 	startISO := start.UTC().Format("2006-01-02T15:04:05Z")
 	endISO := end.UTC().Format("2006-01-02T15:04:05Z")
 
 	where := fmt.Sprintf(`user_id=%d and time_interval in ("%s","%s")`,
-		userID, startISO, endISO)
+		userID, startISO, endISO) + f.where()
+
+	var all, last []TimeEntry
+	seen := make(map[int]bool)
+	for page := 1; ; page++ {
+		entries, err := c.entriesPage(ctx, where, page)
+		if err != nil {
+			return nil, fmt.Errorf("entries page %d: %w", page, err)
+		}
+
+		// A server ignoring paging sends the same full page again. Entries
+		// with IDs tell by having none new, those without by repeating the
+		// last page; either way everything has been sent
+		if page > 1 && repeated(entries, last, seen) {
+			return all, nil
+		}
+		for _, e := range entries {
+			if e.ID != 0 {
+				if seen[e.ID] {
+					continue
+				}
+				seen[e.ID] = true
+			}
+			all = append(all, e)
+		}
+		last = entries
+
+		// A short page is the last, a larger one everything (paging ignored)
+		if len(entries) != c.pageSize {
+			return all, nil
+		}
+	}
+}
+
+// repeated tells whether a page sent nothing new: no unseen IDs, or if its
+// entries have no IDs, the same entries as the page before
+func repeated(entries, last []TimeEntry, seen map[int]bool) bool {
+	withIDs := false
+	for _, e := range entries {
+		if e.ID != 0 {
+			withIDs = true
+			if !seen[e.ID] {
+				return false
+			}
+		}
+	}
+	return withIDs || reflect.DeepEqual(entries, last)
+}
+
+// Fetch a single page (1-based) of entries matching the where clause
+func (c *Client) entriesPage(ctx context.Context, where string, page int) ([]TimeEntry, error) {
+	u, err := url.Parse(c.endpoint("/entries"))
+	if err != nil {
+		return nil, err
+	}
 
 	q := u.Query()
 	q.Set("where", where)
+	q.Set("page", strconv.Itoa(page))
+	q.Set("page_size", strconv.Itoa(c.pageSize))
 	u.RawQuery = q.Encode()
 
-	req, _ := http.NewRequest("GET", u.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
 
 	var out struct {
 		Entries []TimeEntry `json:"entries"`
@@ -132,48 +302,175 @@ func (c *Client) Entries(userID int, start, end time.Time) ([]TimeEntry, error)
 	return out.Entries, nil
 }
 
-// Return a map of projectID to projectName
-func (c *Client) Projects() (map[int]string, error) {
-	req, _ := http.NewRequest("GET", "https://app.paymoapp.com/api/projects", nil)
+// Return a map of projectID to project
+func (c *Client) Projects(ctx context.Context) (map[int]Project, error) {
+	projects, err := list[Project](ctx, c, "projects")
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[int]Project, len(projects))
+	for _, p := range projects {
+		m[p.ID] = p
+	}
+	return m, nil
+}
 
-	var out struct {
-		Projects []Project `json:"projects"`
+// Return a map of userID to user. Paymo only lists the whole team to admins;
+// others get ErrForbidden
+func (c *Client) Users(ctx context.Context) (map[int]User, error) {
+	users, err := list[User](ctx, c, "users")
+	if err != nil {
+		return nil, err
 	}
-	if err := c.do(req, &out); err != nil {
+	m := make(map[int]User, len(users))
+	for _, u := range users {
+		m[u.ID] = u
+	}
+	return m, nil
+}
+
+// Return a map of clientID to client
+func (c *Client) Clients(ctx context.Context) (map[int]Customer, error) {
+	clients, err := list[Customer](ctx, c, "clients")
+	if err != nil {
 		return nil, err
 	}
+	m := make(map[int]Customer, len(clients))
+	for _, cl := range clients {
+		m[cl.ID] = cl
+	}
+	return m, nil
+}
+
+// Return a map of taskID to task
+func (c *Client) Tasks(ctx context.Context) (map[int]Task, error) {
+	tasks, err := list[Task](ctx, c, "tasks")
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[int]Task, len(tasks))
+	for _, t := range tasks {
+		m[t.ID] = t
+	}
+	return m, nil
+}
 
-	m := make(map[int]string, len(out.Projects))
-	for _, p := range out.Projects {
-		m[p.ID] = p.Name
+// Return a map of tasklistID to tasklist
+func (c *Client) TaskLists(ctx context.Context) (map[int]TaskList, error) {
+	lists, err := list[TaskList](ctx, c, "tasklists")
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[int]TaskList, len(lists))
+	for _, l := range lists {
+		m[l.ID] = l
 	}
 	return m, nil
 }
 
-// Centralize HTTP call, parse JSON, and maps 401/403 to ErrUnauthorized (wrapped).
+// Fetch a collection endpoint like /projects, whose response wraps the items
+// in an object keyed by the collection name
+func list[T any](ctx context.Context, c *Client, name string) ([]T, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpoint("/"+name), nil)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string][]T
+	if err := c.do(req, &out); err != nil {
+		return nil, err
+	}
+	return out[name], nil
+}
+
+// Centralize HTTP call, parse JSON, and maps 401 to ErrUnauthorized and 403 to ErrForbidden (wrapped).
+// Throttled, transiently failing and network-failed requests are retried per
+// c.retry; a 429 that outlasts the policy is reported as ErrRateLimited
 func (c *Client) do(req *http.Request, out any) error {
 	req.SetBasicAuth(c.apiKey, "X")
 	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
 
-	resp, err := c.http.Do(req)
-	if err != nil {
-		return err
+	var deadline time.Time
+	if c.retry.MaxElapsed > 0 {
+		deadline = time.Now().Add(c.retry.MaxElapsed)
 	}
-	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
+	for attempt := 1; ; attempt++ {
+		resp, body, err := c.send(req)
 
-	switch resp.StatusCode {
-	case http.StatusOK:
-		if out == nil {
-			return nil
+		// Never retry once the caller gave up
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			return ctxErr
 		}
-		return json.Unmarshal(body, out)
 
-	case http.StatusUnauthorized, http.StatusForbidden: // 401/403
-		return fmt.Errorf("%w: %s", ErrUnauthorized, resp.Status)
+		retryable := err != nil || retryableStatus(resp.StatusCode)
+		if retryable && attempt < c.retry.MaxAttempts {
+			var wait time.Duration
+			var ok bool
+			if err == nil {
+				wait, ok = serverDelay(resp.Header)
+			}
+			if !ok {
+				wait = c.retry.backoff(attempt)
+			}
+			if deadline.IsZero() || time.Now().Add(wait).Before(deadline) {
+				c.log.DebugContext(req.Context(), "retrying request",
+					"url", req.URL.Redacted(), "attempt", attempt, "wait", wait, "reason", retryReason(resp, err))
+				if err := sleep(req.Context(), wait); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if err != nil {
+			return err
+		}
 
-	default:
-		return fmt.Errorf("api %s: %s", resp.Status, string(body))
+		switch resp.StatusCode {
+		case http.StatusOK:
+			if out == nil {
+				return nil
+			}
+			return json.Unmarshal(body, out)
+
+		case http.StatusUnauthorized: // 401
+			return fmt.Errorf("%w: %s", ErrUnauthorized, resp.Status)
+
+		case http.StatusForbidden: // 403, e.g. a non-admin asking for other users' data
+			return fmt.Errorf("%w: %s", ErrForbidden, resp.Status)
+
+		case http.StatusTooManyRequests: // 429 after retries ran out
+			if wait, ok := serverDelay(resp.Header); ok {
+				return fmt.Errorf("%w: retry in %s", ErrRateLimited, wait.Round(time.Second))
+			}
+			return ErrRateLimited
+
+		default:
+			return fmt.Errorf("api %s: %s", resp.Status, string(body))
+		}
+	}
+}
+
+// send performs one attempt and fully reads (and closes) the response body
+func (c *Client) send(req *http.Request) (*http.Response, []byte, error) {
+	// Rewind the body for retries (GET requests have none)
+	if req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, nil, err
+		}
+		req.Body = body
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
 	}
+	return resp, body, nil
 }
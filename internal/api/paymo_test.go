@@ -0,0 +1,218 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api/paymotest"
+)
+
+// window is a few days inside a single chunk, so paging is all that splits requests
+var (
+	windowStart = time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	windowEnd   = time.Date(2026, 3, 6, 23, 59, 59, 0, time.UTC)
+)
+
+// fixtures returns n entries of user 1 inside the window, one per hour
+func fixtures(n int) paymotest.Fixtures {
+	f := paymotest.Fixtures{UserID: 1}
+	for i := range n {
+		f.Entries = append(f.Entries, paymotest.Entry{
+			ID:        1000 + i,
+			UserID:    1,
+			ProjectID: 101,
+			Start:     windowStart.Add(time.Duration(i) * time.Hour),
+			Duration:  30 * time.Minute,
+		})
+	}
+	return f
+}
+
+func testClient(url string, policy RetryPolicy) *Client {
+	return NewClient("test-key", WithBaseURL(url), WithRetryPolicy(policy))
+}
+
+// pagedClient is a test client asking for pages of the given size
+func pagedClient(url string, pageSize int) *Client {
+	c := testClient(url, RetryPolicy{MaxAttempts: 1})
+	c.pageSize = pageSize
+	return c
+}
+
+func TestEntriesPaging(t *testing.T) {
+	tests := []struct {
+		name         string
+		entries      int
+		pageSize     int
+		maxPageSize  int
+		ignorePaging bool
+		wantRequests int
+	}{
+		{name: "no entries", entries: 0, pageSize: 4, wantRequests: 1},
+		{name: "single short page", entries: 3, pageSize: 4, wantRequests: 1},
+		{name: "full pages and a short last one", entries: 10, pageSize: 4, wantRequests: 3},
+		{name: "full pages and an empty last one", entries: 12, pageSize: 4, wantRequests: 4},
+		{name: "one entry per page", entries: 5, pageSize: 1, wantRequests: 6},
+		{name: "server capping at the page size", entries: 10, pageSize: 4, maxPageSize: 4, wantRequests: 3},
+		{name: "default page size", entries: 30, pageSize: entriesPageSize, wantRequests: 1},
+		{name: "paging ignored, fewer than a page", entries: 3, pageSize: 4, ignorePaging: true, wantRequests: 1},
+		{name: "paging ignored, a full page", entries: 4, pageSize: 4, ignorePaging: true, wantRequests: 2},
+		{name: "paging ignored, more than a page", entries: 7, pageSize: 4, ignorePaging: true, wantRequests: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &paymotest.Fake{Fixtures: fixtures(tt.entries), MaxPageSize: tt.maxPageSize, IgnorePaging: tt.ignorePaging}
+			srv := paymotest.NewServer(fake)
+			defer srv.Close()
+
+			got, err := pagedClient(srv.URL, tt.pageSize).Entries(context.Background(), 1, windowStart, windowEnd)
+			if err != nil {
+				t.Fatalf("Entries: %v", err)
+			}
+			if len(got) != tt.entries {
+				t.Errorf("got %d entries, want %d", len(got), tt.entries)
+			}
+			seen := make(map[int]bool)
+			for _, e := range got {
+				if seen[e.ID] {
+					t.Errorf("entry %d returned twice", e.ID)
+				}
+				seen[e.ID] = true
+			}
+			if n := fake.Requests(); n != tt.wantRequests {
+				t.Errorf("made %d requests, want %d", n, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestEntriesPageFailsMidway(t *testing.T) {
+	fake := &paymotest.Fake{Fixtures: fixtures(10)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			http.Error(w, `{"message":"Internal error"}`, http.StatusInternalServerError)
+			return
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	got, err := pagedClient(srv.URL, 4).Entries(context.Background(), 1, windowStart, windowEnd)
+	if err == nil {
+		t.Fatalf("got %d entries and no error, want the failed page reported", len(got))
+	}
+	if !strings.Contains(err.Error(), "entries page 2") {
+		t.Errorf("error %q doesn't name the failed page", err)
+	}
+}
+
+// TestEntriesWithoutIDsAreKept checks entries Paymo sent without an id
+// survive paging, whether the server honours it or not
+func TestEntriesWithoutIDsAreKept(t *testing.T) {
+	tests := []struct {
+		name         string
+		entries      int
+		ignorePaging bool
+		wantRequests int
+	}{
+		{name: "one short page", entries: 1, wantRequests: 1},
+		{name: "several pages", entries: 5, wantRequests: 3},
+		{name: "paging ignored, fewer than a page", entries: 1, ignorePaging: true, wantRequests: 1},
+		{name: "paging ignored, a full page", entries: 2, ignorePaging: true, wantRequests: 2},
+	}
+	const pageSize = 2
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				from, to := 0, tt.entries
+				if !tt.ignorePaging {
+					page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+					from, to = min((page-1)*pageSize, tt.entries), min(page*pageSize, tt.entries)
+				}
+				var entries []string
+				for i := from; i < to; i++ {
+					entries = append(entries, fmt.Sprintf(`{"project_id":101,"duration":%d,"date":"2026-03-02"}`, 600+i))
+				}
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(w, `{"entries":[%s]}`, strings.Join(entries, ","))
+			}))
+			defer srv.Close()
+
+			got, err := pagedClient(srv.URL, pageSize).Entries(context.Background(), 1, windowStart, windowEnd)
+			if err != nil {
+				t.Fatalf("Entries: %v", err)
+			}
+			if len(got) != tt.entries {
+				t.Errorf("got %d entries, want all %d without an ID", len(got), tt.entries)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("made %d requests, want %d", requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+// TestMeStatus checks a key Paymo refuses on /me is reported as
+// unauthorized, whether it answers 401 or 403
+func TestMeStatus(t *testing.T) {
+	tests := []struct {
+		status       int
+		unauthorized bool
+	}{
+		{http.StatusUnauthorized, true},
+		{http.StatusForbidden, true},
+		{http.StatusNotFound, false},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			_, err := testClient(srv.URL, RetryPolicy{MaxAttempts: 1}).Me(context.Background())
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if got := errors.Is(err, ErrUnauthorized); got != tt.unauthorized {
+				t.Errorf("errors.Is(%v, ErrUnauthorized) = %v", err, got)
+			}
+		})
+	}
+}
+
+// TestEnableDebug checks the old debug switch still traces to the standard
+// logger, without the API key
+func TestEnableDebug(t *testing.T) {
+	var out strings.Builder
+	log.SetOutput(&out)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	srv := paymotest.NewServer(&paymotest.Fake{Fixtures: paymotest.Fixtures{UserID: 1, Users: []paymotest.User{{ID: 1, Name: "Demo"}}}})
+	defer srv.Close()
+
+	c := testClient(srv.URL, RetryPolicy{MaxAttempts: 1})
+	c.EnableDebug()
+	if _, err := c.Me(context.Background()); err != nil {
+		t.Fatalf("Me: %v", err)
+	}
+	for _, want := range []string{"http request headers", "http response body", "status=200", "REDACTED"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("log has no %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "test-key") {
+		t.Errorf("log has the API key:\n%s", out.String())
+	}
+}
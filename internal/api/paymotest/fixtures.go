@@ -0,0 +1,198 @@
+package paymotest
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Fixtures is the data a Fake serves
+type Fixtures struct {
+	UserID    int // the user /me returns
+	Users     []User
+	Clients   []Client
+	Projects  []Project
+	TaskLists []TaskList
+	Tasks     []Task
+	Entries   []Entry
+}
+
+type User struct {
+	ID    int
+	Name  string
+	Email string
+	Rate  float64 // hourly rate; 0 for none
+}
+
+type Client struct {
+	ID   int
+	Name string
+}
+
+type Project struct {
+	ID       int
+	Name     string
+	ClientID int
+	Billable bool
+	Rate     float64 // hourly rate; 0 for none
+	Currency string
+	Budget   float64 // budget hours; 0 for none
+}
+
+type TaskList struct {
+	ID        int
+	Name      string
+	ProjectID int
+}
+
+type Task struct {
+	ID         int
+	Name       string
+	ProjectID  int
+	TaskListID int
+	Billable   *bool   // nil inherits the project's setting
+	Rate       float64 // hourly rate over the project's; 0 for none
+}
+
+type Entry struct {
+	ID          int
+	UserID      int
+	ProjectID   int
+	TaskID      int
+	Start       time.Time
+	Duration    time.Duration
+	Description string
+	Tags        []string
+	Billable    *bool   // nil inherits the task's setting
+	Rate        float64 // hourly rate over every other; 0 for none
+	// Manual entries only carry a date (like Paymo's "add time" entries),
+	// timer entries carry start_time/end_time
+	Manual bool
+}
+
+// End returns when the entry stops
+func (e Entry) End() time.Time {
+	return e.Start.Add(e.Duration)
+}
+
+// taskNames are the tasks of every demo project, by tasklist
+var taskNames = [][]string{{"Implementation", "Code review"}, {"Planning", "Standups"}}
+
+// descriptions are the entry descriptions per task, like taskNames
+var descriptions = [][][]string{
+	{
+		{"Feature work", "Bug fixes", "Onboarding flow", "Refactoring", "Release prep"},
+		{"Review pull requests", "Review onboarding changes", "Pair review"},
+	},
+	{
+		{"Sprint planning", "Estimate tickets", "Client onboarding call", "Roadmap"},
+		{"Daily standup"},
+	},
+}
+
+// descriptionTags are the tags of entries with these descriptions
+var descriptionTags = map[string][]string{
+	"Bug fixes":              {"bugfix"},
+	"Release prep":           {"release", "urgent"},
+	"Client onboarding call": {"call"},
+	"Roadmap":                {"planning"},
+}
+
+// Demo returns deterministic fixtures for offline demos: a handful of
+// projects with tasks and roughly two years of weekday entries for user 1
+// and two teammates, ending at now
+func Demo(now time.Time) Fixtures {
+	f := Fixtures{
+		UserID: 1,
+		Users: []User{
+			{ID: 1, Name: "Demo User", Email: "demo@example.com", Rate: 80},
+			{ID: 2, Name: "Alex Kim", Email: "alex@example.com", Rate: 95},
+			{ID: 3, Name: "Sam Rivera", Email: "sam@example.com", Rate: 70},
+		},
+		Clients: []Client{
+			{ID: 11, Name: "Acme Corp"},
+			{ID: 12, Name: "Globex"},
+		},
+		Projects: []Project{
+			{ID: 101, Name: "Website Relaunch", ClientID: 11, Billable: true, Rate: 120, Currency: "EUR", Budget: 2000},
+			{ID: 102, Name: "Mobile App", ClientID: 12, Billable: true, Rate: 150, Currency: "USD", Budget: 1800},
+			{ID: 103, Name: "Internal Tools", Currency: "EUR"},
+			{ID: 104, Name: "Client Support", ClientID: 11, Billable: true, Rate: 90, Currency: "EUR", Budget: 2400},
+			{ID: 105, Name: "Onboarding", Currency: "EUR"},
+		},
+	}
+
+	// Every project gets the same two tasklists with two tasks each.
+	// Standups are never billable, whatever the project's setting, and
+	// code reviews are billed a little below the project's rate
+	notBillable := false
+	listNames := []string{"Development", "Meetings"}
+	for _, p := range f.Projects {
+		for li, ln := range listNames {
+			list := TaskList{ID: p.ID*10 + li, Name: ln, ProjectID: p.ID}
+			f.TaskLists = append(f.TaskLists, list)
+			for ti, tn := range taskNames[li] {
+				t := Task{ID: p.ID*100 + li*10 + ti, Name: tn, ProjectID: p.ID, TaskListID: list.ID}
+				switch {
+				case tn == "Standups":
+					t.Billable = &notBillable
+				case tn == "Code review" && p.Rate != 0:
+					t.Rate = p.Rate - 20
+				}
+				f.Tasks = append(f.Tasks, t)
+			}
+		}
+	}
+
+	// Each user draws from their own source (user 1 keeps the original
+	// seed), so adding teammates never changes user 1's entries
+	id := 1000
+	for _, u := range f.Users {
+		id = f.addEntries(rand.New(rand.NewPCG(42, uint64(6+u.ID))), u.ID, now, id)
+	}
+	return f
+}
+
+// addEntries adds two years of weekday entries up to now for a user, with
+// IDs after lastID, and returns the last ID used
+func (f *Fixtures) addEntries(r *rand.Rand, userID int, now time.Time, lastID int) int {
+	id := lastID
+	notBillable := false
+	day := time.Date(now.Year()-2, now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for ; day.Before(now); day = day.AddDate(0, 0, 1) {
+		if wd := day.Weekday(); wd == time.Saturday || wd == time.Sunday {
+			continue
+		}
+		start := day.Add(9 * time.Hour)
+		for range 2 + r.IntN(3) {
+			p := f.Projects[r.IntN(len(f.Projects))]
+			list, task := r.IntN(2), r.IntN(2)
+			d := time.Duration(30+r.IntN(180)) * time.Minute
+			id++
+			// Descriptions come from the ID rather than r, so the other
+			// fields stay as they were before entries had descriptions
+			texts := descriptions[list][task]
+			e := Entry{
+				ID:          id,
+				UserID:      userID,
+				ProjectID:   p.ID,
+				TaskID:      p.ID*100 + list*10 + task,
+				Start:       start,
+				Duration:    d,
+				Description: texts[id%len(texts)],
+				Manual:      r.IntN(10) == 0,
+			}
+			e.Tags = descriptionTags[e.Description]
+			// Pair reviews are written off and release prep is billed at a
+			// rush rate, whatever the task and project say
+			switch {
+			case e.Description == "Pair review":
+				e.Billable = &notBillable
+			case e.Description == "Release prep" && p.Rate != 0:
+				e.Rate = p.Rate * 1.5
+			}
+			f.Entries = append(f.Entries, e)
+			start = start.Add(d)
+		}
+	}
+	return id
+}
@@ -0,0 +1,307 @@
+// Package paymotest provides an in-memory fake of the Paymo API for tests
+// and offline demos. Point an api.Client at it with api.WithBaseURL
+package paymotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fake serves Fixtures over a Paymo-shaped HTTP API. The exported knobs can
+// be set before serving to inject the failures real Paymo produces
+type Fake struct {
+	Fixtures Fixtures
+
+	// APIKey, if set, is the only key accepted; other keys get a 401
+	APIKey string
+	// Unauthorized answers every request with 401
+	Unauthorized bool
+	// RateLimit answers the first N requests with 429 and RetryAfter
+	RateLimit  int
+	RetryAfter time.Duration
+	// MaxPageSize caps entries per page regardless of the requested page_size;
+	// 0 means no cap. A cap below the requested size looks like a last page to
+	// the client. IgnorePaging sends all matching entries on every page
+	MaxPageSize  int
+	IgnorePaging bool
+	// MalformedTimestamps sends timestamps Paymo's clients can't parse
+	MalformedTimestamps bool
+	// Member acts like a non-admin key: /users and other users' entries get a 403
+	Member bool
+
+	mu       sync.Mutex
+	requests int
+}
+
+// NewServer starts an httptest.Server backed by f. Callers must Close it
+func NewServer(f *Fake) *httptest.Server {
+	return httptest.NewServer(f)
+}
+
+// Requests returns how many requests the fake has received
+func (f *Fake) Requests() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests
+}
+
+func (f *Fake) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requests++
+	throttled := f.requests <= f.RateLimit
+	f.mu.Unlock()
+
+	if key, _, ok := r.BasicAuth(); f.Unauthorized || !ok || (f.APIKey != "" && key != f.APIKey) {
+		http.Error(w, `{"message":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+	if throttled {
+		w.Header().Set("Retry-After", strconv.Itoa(int(f.RetryAfter.Seconds())))
+		w.Header().Set("X-Ratelimit-Remaining", "0")
+		http.Error(w, `{"message":"Rate limit exceeded"}`, http.StatusTooManyRequests)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, `{"message":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch strings.TrimSuffix(r.URL.Path, "/") {
+	case "/me":
+		me := map[string]any{"id": f.Fixtures.UserID}
+		for _, u := range f.Fixtures.Users {
+			if u.ID == f.Fixtures.UserID {
+				me = userJSON(u)
+			}
+		}
+		writeJSON(w, map[string]any{"users": []map[string]any{me}})
+	case "/users":
+		if f.Member {
+			http.Error(w, `{"message":"You are not allowed to list users"}`, http.StatusForbidden)
+			return
+		}
+		users := make([]map[string]any, 0, len(f.Fixtures.Users))
+		for _, u := range f.Fixtures.Users {
+			users = append(users, userJSON(u))
+		}
+		writeJSON(w, map[string]any{"users": users})
+	case "/clients":
+		clients := make([]map[string]any, 0, len(f.Fixtures.Clients))
+		for _, c := range f.Fixtures.Clients {
+			clients = append(clients, map[string]any{"id": c.ID, "name": c.Name})
+		}
+		writeJSON(w, map[string]any{"clients": clients})
+	case "/projects":
+		projects := make([]map[string]any, 0, len(f.Fixtures.Projects))
+		for _, p := range f.Fixtures.Projects {
+			m := map[string]any{"id": p.ID, "name": p.Name, "client_id": nil, "billable": p.Billable, "price_per_hour": nil, "currency": p.Currency}
+			if p.ClientID != 0 {
+				m["client_id"] = p.ClientID
+			}
+			if p.Rate != 0 {
+				m["price_per_hour"] = p.Rate
+			}
+			if p.Budget != 0 {
+				m["budget_hours"] = p.Budget
+			}
+			projects = append(projects, m)
+		}
+		writeJSON(w, map[string]any{"projects": projects})
+	case "/tasklists":
+		lists := make([]map[string]any, 0, len(f.Fixtures.TaskLists))
+		for _, l := range f.Fixtures.TaskLists {
+			lists = append(lists, map[string]any{"id": l.ID, "name": l.Name, "project_id": l.ProjectID})
+		}
+		writeJSON(w, map[string]any{"tasklists": lists})
+	case "/tasks":
+		tasks := make([]map[string]any, 0, len(f.Fixtures.Tasks))
+		for _, t := range f.Fixtures.Tasks {
+			m := map[string]any{"id": t.ID, "name": t.Name, "project_id": t.ProjectID, "tasklist_id": t.TaskListID}
+			if t.Billable != nil {
+				m["billable"] = *t.Billable
+			}
+			if t.Rate != 0 {
+				m["price_per_hour"] = t.Rate
+			}
+			tasks = append(tasks, m)
+		}
+		writeJSON(w, map[string]any{"tasks": tasks})
+	case "/entries":
+		f.serveEntries(w, r)
+	default:
+		http.Error(w, `{"message":"Not found"}`, http.StatusNotFound)
+	}
+}
+
+func (f *Fake) serveEntries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	match, err := parseWhere(q.Get("where"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"message":%q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	var matched []Entry
+	for _, e := range f.Fixtures.Entries {
+		if match(e) {
+			if f.Member && e.UserID != f.Fixtures.UserID {
+				http.Error(w, `{"message":"You are not allowed to view other users' time entries"}`, http.StatusForbidden)
+				return
+			}
+			matched = append(matched, e)
+		}
+	}
+
+	if !f.IgnorePaging && q.Has("page") {
+		page, _ := strconv.Atoi(q.Get("page"))
+		size, _ := strconv.Atoi(q.Get("page_size"))
+		if f.MaxPageSize > 0 && (size <= 0 || size > f.MaxPageSize) {
+			size = f.MaxPageSize
+		}
+		if page >= 1 && size > 0 {
+			from := min((page-1)*size, len(matched))
+			matched = matched[from:min(from+size, len(matched))]
+		}
+	}
+
+	entries := make([]map[string]any, 0, len(matched))
+	for _, e := range matched {
+		entries = append(entries, f.entryJSON(e))
+	}
+	writeJSON(w, map[string]any{"entries": entries})
+}
+
+func userJSON(u User) map[string]any {
+	m := map[string]any{"id": u.ID, "name": u.Name, "email": u.Email, "price_per_hour": nil}
+	if u.Rate != 0 {
+		m["price_per_hour"] = u.Rate
+	}
+	return m
+}
+
+// entryJSON renders an entry the way Paymo does: timer entries with ISO
+// start/end times, manual ones with a date only
+func (f *Fake) entryJSON(e Entry) map[string]any {
+	m := map[string]any{
+		"id":         e.ID,
+		"user_id":    e.UserID,
+		"project_id": e.ProjectID,
+		"task_id":    e.TaskID,
+		"duration":   int(e.Duration.Seconds()),
+	}
+	if e.Description != "" {
+		m["description"] = e.Description
+	}
+	if len(e.Tags) > 0 {
+		m["tags"] = e.Tags
+	}
+	if e.Billable != nil {
+		m["billable"] = *e.Billable
+	}
+	if e.Rate != 0 {
+		m["price_per_hour"] = e.Rate
+	}
+	switch {
+	case f.MalformedTimestamps:
+		m["start_time"] = "yesterday-ish"
+	case e.Manual:
+		m["date"] = e.Start.UTC().Format("2006-01-02")
+	default:
+		m["start_time"] = e.Start.UTC().Format(time.RFC3339)
+		m["end_time"] = e.End().UTC().Format(time.RFC3339)
+	}
+	return m
+}
+
+var (
+	reUserID   = regexp.MustCompile(`^user_id\s*=\s*(\d+)$`)
+	reInterval = regexp.MustCompile(`^time_interval\s+in\s+\(\s*"([^"]+)"\s*,\s*"([^"]+)"\s*\)$`)
+	reIDs      = regexp.MustCompile(`^(project_id|task_id)\s+in\s+\(([\d,\s]+)\)$`)
+	reLike     = regexp.MustCompile(`^description\s+like\s+"%([^"%]*)%"$`)
+)
+
+// parseWhere understands the subset of Paymo's where syntax the client sends:
+// clauses joined by "and". Unknown clauses are an error, so client bugs surface
+func parseWhere(where string) (func(Entry) bool, error) {
+	var preds []func(Entry) bool
+	for _, clause := range splitAnd(where) {
+		clause = strings.TrimSpace(clause)
+		switch {
+		case clause == "":
+		case reUserID.MatchString(clause):
+			id, _ := strconv.Atoi(reUserID.FindStringSubmatch(clause)[1])
+			preds = append(preds, func(e Entry) bool { return e.UserID == id })
+		case reInterval.MatchString(clause):
+			m := reInterval.FindStringSubmatch(clause)
+			from, err1 := time.Parse(time.RFC3339, m[1])
+			to, err2 := time.Parse(time.RFC3339, m[2])
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("invalid time_interval in %q", clause)
+			}
+			preds = append(preds, func(e Entry) bool {
+				// Overlap with [from, to], like Paymo's time_interval
+				return !e.Start.After(to) && !e.End().Before(from)
+			})
+		case reIDs.MatchString(clause):
+			m := reIDs.FindStringSubmatch(clause)
+			ids := make(map[int]bool)
+			for _, s := range strings.Split(m[2], ",") {
+				id, err := strconv.Atoi(strings.TrimSpace(s))
+				if err != nil {
+					return nil, fmt.Errorf("invalid ID list in %q", clause)
+				}
+				ids[id] = true
+			}
+			if m[1] == "project_id" {
+				preds = append(preds, func(e Entry) bool { return ids[e.ProjectID] })
+			} else {
+				preds = append(preds, func(e Entry) bool { return ids[e.TaskID] })
+			}
+		case reLike.MatchString(clause):
+			// Like MySQL's default collation, case insensitive
+			text := strings.ToLower(reLike.FindStringSubmatch(clause)[1])
+			preds = append(preds, func(e Entry) bool { return strings.Contains(strings.ToLower(e.Description), text) })
+		default:
+			return nil, fmt.Errorf("unsupported where clause %q", clause)
+		}
+	}
+	return func(e Entry) bool {
+		for _, p := range preds {
+			if !p(e) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// splitAnd splits a where clause at the "and"s outside quoted values, so a
+// description containing " and " stays one clause. Paymo has no escaping in
+// quotes, so every quote opens or closes one
+func splitAnd(where string) []string {
+	var clauses []string
+	quoted, from := false, 0
+	for i := 0; i < len(where); i++ {
+		switch {
+		case where[i] == '"':
+			quoted = !quoted
+		case !quoted && strings.HasPrefix(where[i:], " and "):
+			clauses = append(clauses, where[from:i])
+			from = i + len(" and ")
+			i = from - 1
+		}
+	}
+	return append(clauses, where[from:])
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
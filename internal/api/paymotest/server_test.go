@@ -0,0 +1,202 @@
+package paymotest_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+	"github.com/Ma-Kas/paymostats/internal/api/paymotest"
+)
+
+var now = time.Date(2026, 3, 20, 18, 0, 0, 0, time.UTC)
+
+func client(url string) *api.Client {
+	return api.NewClient("demo", api.WithBaseURL(url), api.WithRetryPolicy(api.RetryPolicy{MaxAttempts: 1}))
+}
+
+func TestKnobs(t *testing.T) {
+	start, end := now.AddDate(0, 0, -10), now
+	me := func(c *api.Client) error { _, err := c.Me(context.Background()); return err }
+	users := func(c *api.Client) error { _, err := c.Users(context.Background()); return err }
+	entries := func(c *api.Client) error { _, err := c.Entries(context.Background(), 1, start, end); return err }
+	teammate := func(c *api.Client) error { _, err := c.Entries(context.Background(), 2, start, end); return err }
+
+	tests := []struct {
+		name    string
+		knobs   func(*paymotest.Fake)
+		call    func(*api.Client) error
+		wantErr error // nil for success
+		anyErr  bool  // some error that isn't one of the api sentinels
+	}{
+		{name: "defaults", call: me},
+		{name: "Unauthorized", knobs: func(f *paymotest.Fake) { f.Unauthorized = true }, call: me, wantErr: api.ErrUnauthorized},
+		{name: "APIKey rejects other keys", knobs: func(f *paymotest.Fake) { f.APIKey = "other" }, call: me, wantErr: api.ErrUnauthorized},
+		{name: "APIKey accepts its key", knobs: func(f *paymotest.Fake) { f.APIKey = "demo" }, call: me},
+		{name: "RateLimit", knobs: func(f *paymotest.Fake) { f.RateLimit = 1 }, call: me, wantErr: api.ErrRateLimited},
+		{name: "Member can't list users", knobs: func(f *paymotest.Fake) { f.Member = true }, call: users, wantErr: api.ErrForbidden},
+		{name: "Member can't see teammates' entries", knobs: func(f *paymotest.Fake) { f.Member = true }, call: teammate, wantErr: api.ErrForbidden},
+		{name: "Member sees own entries", knobs: func(f *paymotest.Fake) { f.Member = true }, call: entries},
+		{name: "MalformedTimestamps", knobs: func(f *paymotest.Fake) { f.MalformedTimestamps = true }, call: entries, anyErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &paymotest.Fake{Fixtures: paymotest.Demo(now)}
+			if tt.knobs != nil {
+				tt.knobs(fake)
+			}
+			srv := paymotest.NewServer(fake)
+			defer srv.Close()
+
+			err := tt.call(client(srv.URL))
+			switch {
+			case tt.anyErr:
+				if err == nil || errors.Is(err, api.ErrUnauthorized) || errors.Is(err, api.ErrForbidden) || errors.Is(err, api.ErrRateLimited) {
+					t.Errorf("got %v, want a plain error", err)
+				}
+			case tt.wantErr == nil && err != nil:
+				t.Errorf("got %v, want no error", err)
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestWhere checks the fake applies the where clauses the client sends
+func TestWhere(t *testing.T) {
+	fixtures := paymotest.Demo(now)
+	srv := paymotest.NewServer(&paymotest.Fake{Fixtures: fixtures})
+	defer srv.Close()
+
+	start, end := now.AddDate(0, -2, 0), now
+	filter := api.EntryFilter{ProjectIDs: []int{101, 104}, Description: "ONBOARDING"}
+	tests := []struct {
+		name   string
+		userID int
+		filter api.EntryFilter
+		keep   func(paymotest.Entry) bool
+	}{
+		{"user and interval", 2, api.EntryFilter{}, func(e paymotest.Entry) bool { return true }},
+		{"projects and description", 1, filter, func(e paymotest.Entry) bool {
+			return (e.ProjectID == 101 || e.ProjectID == 104) &&
+				(e.Description == "Onboarding flow" || e.Description == "Review onboarding changes" || e.Description == "Client onboarding call")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := 0
+			for _, e := range fixtures.Entries {
+				if e.UserID == tt.userID && !e.Start.After(end) && !e.End().Before(start) && tt.keep(e) {
+					want++
+				}
+			}
+			got, err := client(srv.URL).FilteredEntries(context.Background(), tt.userID, start, end, tt.filter)
+			if err != nil {
+				t.Fatalf("FilteredEntries: %v", err)
+			}
+			if want == 0 {
+				t.Fatal("fixtures have no matching entries, the test checks nothing")
+			}
+			if len(got) != want {
+				t.Errorf("got %d entries, want %d", len(got), want)
+			}
+			for _, e := range got {
+				if e.UserID != tt.userID {
+					t.Errorf("entry %d is user %d's, want only user %d's", e.ID, e.UserID, tt.userID)
+				}
+			}
+		})
+	}
+}
+
+// TestWhereQuotedAnd checks an "and" inside a quoted description doesn't
+// split the clause
+func TestWhereQuotedAnd(t *testing.T) {
+	day := time.Date(2026, 3, 16, 9, 0, 0, 0, time.UTC)
+	fixtures := paymotest.Fixtures{UserID: 1, Entries: []paymotest.Entry{
+		{ID: 1, UserID: 1, ProjectID: 101, Start: day, Duration: time.Hour, Description: "Review and merge"},
+		{ID: 2, UserID: 1, ProjectID: 101, Start: day, Duration: time.Hour, Description: "Review"},
+		{ID: 3, UserID: 1, ProjectID: 102, Start: day, Duration: time.Hour, Description: "Review and merge"},
+	}}
+	srv := paymotest.NewServer(&paymotest.Fake{Fixtures: fixtures})
+	defer srv.Close()
+
+	filter := api.EntryFilter{Description: "review and merge", ProjectIDs: []int{101}}
+	got, err := client(srv.URL).FilteredEntries(context.Background(), 1, day, day.Add(time.Hour), filter)
+	if err != nil {
+		t.Fatalf("FilteredEntries: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != 1 {
+		t.Errorf("got %+v, want entry 1 only", got)
+	}
+}
+
+// TestEntryFields checks the overrides of entries and tasks reach the client
+func TestEntryFields(t *testing.T) {
+	fixtures := paymotest.Demo(now)
+	srv := paymotest.NewServer(&paymotest.Fake{Fixtures: fixtures})
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := client(srv.URL)
+	entries, err := c.Entries(ctx, 1, now.AddDate(0, -1, 0), now)
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	want := make(map[int]paymotest.Entry)
+	for _, e := range fixtures.Entries {
+		want[e.ID] = e
+	}
+	var billable, rated, tagged int
+	for _, e := range entries {
+		f := want[e.ID]
+		if (e.Billable == nil) != (f.Billable == nil) || e.Billable != nil && *e.Billable != *f.Billable {
+			t.Errorf("entry %d billable %v, want %v", e.ID, e.Billable, f.Billable)
+		}
+		if e.Billable != nil {
+			billable++
+		}
+		if got := rate(e.PricePerHour); got != f.Rate {
+			t.Errorf("entry %d rate %v, want %v", e.ID, got, f.Rate)
+		}
+		if f.Rate != 0 {
+			rated++
+		}
+		if !slices.Equal(e.Tags, f.Tags) {
+			t.Errorf("entry %d tags %v, want %v", e.ID, e.Tags, f.Tags)
+		}
+		if len(f.Tags) > 0 {
+			tagged++
+		}
+	}
+	if billable == 0 || rated == 0 || tagged == 0 {
+		t.Errorf("%d billable flags, %d rates and %d tagged entries, the test checks too little", billable, rated, tagged)
+	}
+
+	tasks, err := c.Tasks(ctx)
+	if err != nil {
+		t.Fatalf("Tasks: %v", err)
+	}
+	rated = 0
+	for _, f := range fixtures.Tasks {
+		if got := rate(tasks[f.ID].PricePerHour); got != f.Rate {
+			t.Errorf("task %d rate %v, want %v", f.ID, got, f.Rate)
+		}
+		if f.Rate != 0 {
+			rated++
+		}
+	}
+	if rated == 0 {
+		t.Error("no task has a rate, the test checks nothing")
+	}
+}
+
+func rate(r *float64) float64 {
+	if r == nil {
+		return 0
+	}
+	return *r
+}
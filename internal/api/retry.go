@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how Client.do retries throttled (429), transiently
+// failing (502/503/504) and network-failed requests
+type RetryPolicy struct {
+	MaxAttempts int           // total tries including the first; <= 1 disables retries
+	MaxElapsed  time.Duration // give up once the next wait would cross this deadline; 0 = no deadline
+	BaseDelay   time.Duration // first backoff step, doubled per attempt
+	MaxDelay    time.Duration // cap for a single backoff step
+}
+
+// DefaultRetryPolicy is used by NewClient
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	MaxElapsed:  2 * time.Minute,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+}
+
+// SetRetryPolicy replaces the client's retry policy
+func (c *Client) SetRetryPolicy(p RetryPolicy) {
+	c.retry = p
+}
+
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff returns a fully jittered exponential delay for the given attempt (1-based)
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay << (attempt - 1)
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d) + 1
+}
+
+// serverDelay reads how long the server asked us to wait: the standard
+// Retry-After header (seconds or HTTP date), falling back to Paymo's
+// X-Ratelimit-Decay-Period when the quota is used up. ok is false if neither is set
+func serverDelay(h http.Header) (time.Duration, bool) {
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return max(time.Until(t), 0), true
+		}
+	}
+	if h.Get("X-Ratelimit-Remaining") == "0" {
+		if secs, err := strconv.Atoi(h.Get("X-Ratelimit-Decay-Period")); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second, true
+		}
+	}
+	return 0, false
+}
+
+// sleep waits for d, returning early with the context's error if it's canceled
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// retryReason describes why an attempt is retried, for logging
+func retryReason(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return resp.Status
+}
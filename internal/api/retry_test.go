@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api/paymotest"
+)
+
+func TestRetry(t *testing.T) {
+	fast := RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+	tests := []struct {
+		name        string
+		rateLimit   int           // first requests the fake throttles
+		retryAfter  time.Duration // Retry-After the fake sends with them
+		statuses    []int         // statuses answered before the fake, one per request
+		policy      RetryPolicy
+		cancelAfter time.Duration // cancel the context after this long; 0 never
+
+		wantErr      error
+		wantRequests int
+		minElapsed   time.Duration
+		maxElapsed   time.Duration
+	}{
+		{
+			name:      "Retry-After is honoured",
+			rateLimit: 1, retryAfter: time.Second, policy: fast,
+			wantRequests: 2, minElapsed: time.Second, maxElapsed: 5 * time.Second,
+		},
+		{
+			name:      "attempts run out",
+			rateLimit: 10, policy: RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			wantErr: ErrRateLimited, wantRequests: 3, maxElapsed: time.Second,
+		},
+		{
+			name:     "502, 503 and 504 are retried",
+			statuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}, policy: fast,
+			wantRequests: 4, maxElapsed: time.Second,
+		},
+		{
+			name:     "500 isn't retried",
+			statuses: []int{http.StatusInternalServerError}, policy: fast,
+			wantErr: errAny, wantRequests: 1, maxElapsed: time.Second,
+		},
+		{
+			name:      "MaxElapsed stops retrying",
+			rateLimit: 10, retryAfter: 5 * time.Second,
+			policy:  RetryPolicy{MaxAttempts: 10, MaxElapsed: time.Second, BaseDelay: time.Millisecond},
+			wantErr: ErrRateLimited, wantRequests: 1, maxElapsed: time.Second,
+		},
+		{
+			name:      "canceling during the backoff returns promptly",
+			rateLimit: 10, retryAfter: 30 * time.Second, policy: RetryPolicy{MaxAttempts: 5},
+			cancelAfter: 50 * time.Millisecond,
+			wantErr:     context.Canceled, wantRequests: 1, maxElapsed: time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &paymotest.Fake{Fixtures: paymotest.Fixtures{UserID: 1}, RateLimit: tt.rateLimit, RetryAfter: tt.retryAfter}
+			var requests atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if n := int(requests.Add(1)); n <= len(tt.statuses) {
+					http.Error(w, http.StatusText(tt.statuses[n-1]), tt.statuses[n-1])
+					return
+				}
+				fake.ServeHTTP(w, r)
+			}))
+			defer srv.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelAfter > 0 {
+				time.AfterFunc(tt.cancelAfter, cancel)
+			}
+
+			began := time.Now()
+			_, err := testClient(srv.URL, tt.policy).Me(ctx)
+			elapsed := time.Since(began)
+
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Errorf("got error %v, want none", err)
+			case tt.wantErr == errAny && err == nil:
+				t.Error("got no error, want one")
+			case tt.wantErr != nil && tt.wantErr != errAny && !errors.Is(err, tt.wantErr):
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			}
+			if n := int(requests.Load()); n != tt.wantRequests {
+				t.Errorf("made %d requests, want %d", n, tt.wantRequests)
+			}
+			if elapsed < tt.minElapsed || elapsed > tt.maxElapsed {
+				t.Errorf("took %s, want between %s and %s", elapsed, tt.minElapsed, tt.maxElapsed)
+			}
+		})
+	}
+}
+
+// errAny stands for any error in TestRetry
+var errAny = errors.New("any error")
+
+func TestServerDelay(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+		wantOK bool
+	}{
+		{"seconds", http.Header{"Retry-After": {"7"}}, 7 * time.Second, true},
+		{"date in the past", http.Header{"Retry-After": {"Mon, 02 Jan 2006 15:04:05 GMT"}}, 0, true},
+		{"decay period once the quota is used up", http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Decay-Period": {"3"}}, 3 * time.Second, true},
+		{"decay period with quota left", http.Header{"X-Ratelimit-Remaining": {"5"}, "X-Ratelimit-Decay-Period": {"3"}}, 0, false},
+		{"nothing", http.Header{}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := serverDelay(tt.header)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("serverDelay = %s, %t; want %s, %t", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package cache
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+)
+
+const (
+	// A day is settled once it was fetched this long after it ended. Late
+	// edits to older days are rare, so settled days are trusted for settledTTL
+	settleAfter = 7 * 24 * time.Hour
+	settledTTL  = 30 * 24 * time.Hour
+	// Days that may still change are refetched after recentTTL
+	recentTTL = time.Hour
+)
+
+// span is a run of days (UTC, inclusive) fetched in one go
+type span struct {
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+func (sp span) covers(d time.Time) bool {
+	return !d.Before(sp.From) && !d.After(sp.To)
+}
+
+// settled reports whether every day of the span was fetched after it settled
+func (sp span) settled() bool {
+	return sp.FetchedAt.After(sp.To.AddDate(0, 0, 1).Add(settleAfter))
+}
+
+// entriesFile is one user's cached entries, keyed by day ("2006-01-02"),
+// plus the spans recording which days have been fetched and when
+type entriesFile struct {
+	Version int                        `json:"version"`
+	Spans   []span                     `json:"spans"`
+	Days    map[string][]api.TimeEntry `json:"days"`
+}
+
+// day truncates t to midnight UTC
+func day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func dayKey(d time.Time) string {
+	return d.Format("2006-01-02")
+}
+
+// fresh reports whether day d is cached and still within its TTL
+func (f *entriesFile) fresh(d, now time.Time) bool {
+	sp := f.covering(d)
+	if sp == nil {
+		return false
+	}
+	ttl := recentTTL
+	if sp.FetchedAt.After(d.AddDate(0, 0, 1).Add(settleAfter)) {
+		ttl = settledTTL
+	}
+	return now.Sub(sp.FetchedAt) < ttl
+}
+
+// staleRuns returns the runs of consecutive days in [from, to] that need fetching
+func (f *entriesFile) staleRuns(from, to, now time.Time) []span {
+	return dayRuns(from, to, func(d time.Time) bool { return !f.fresh(d, now) })
+}
+
+// missingRuns returns the runs of days in [from, to] that were never fetched
+func (f *entriesFile) missingRuns(from, to time.Time) []span {
+	return dayRuns(from, to, func(d time.Time) bool { return f.covering(d) == nil })
+}
+
+// covering returns the span that covers day d, or nil
+func (f *entriesFile) covering(d time.Time) *span {
+	for i := range f.Spans {
+		if f.Spans[i].covers(d) {
+			return &f.Spans[i]
+		}
+	}
+	return nil
+}
+
+// dayRuns groups the days in [from, to] matching pred into runs of consecutive days
+func dayRuns(from, to time.Time, pred func(time.Time) bool) []span {
+	var runs []span
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		if !pred(d) {
+			continue
+		}
+		if n := len(runs); n > 0 && runs[n-1].To.AddDate(0, 0, 1).Equal(d) {
+			runs[n-1].To = d
+		} else {
+			runs = append(runs, span{From: d, To: d})
+		}
+	}
+	return runs
+}
+
+// record replaces the days [from, to] with freshly fetched entries
+func (f *entriesFile) record(from, to, fetchedAt time.Time, entries []api.TimeEntry) {
+	if f.Days == nil {
+		f.Days = make(map[string][]api.TimeEntry)
+	}
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		delete(f.Days, dayKey(d))
+	}
+	for _, e := range entries {
+		d := day(e.Time())
+		if d.Before(from) || d.After(to) {
+			// Overlaps the run but belongs to a neighbouring day (or has no
+			// timestamp at all); file it under the nearest day of the run
+			if d.Before(from) {
+				d = from
+			} else {
+				d = to
+			}
+		}
+		f.Days[dayKey(d)] = append(f.Days[dayKey(d)], e)
+	}
+
+	// Cut the new run out of older spans, then add it
+	var spans []span
+	for _, sp := range f.Spans {
+		if sp.To.Before(from) || sp.From.After(to) {
+			spans = append(spans, sp)
+			continue
+		}
+		if sp.From.Before(from) {
+			spans = append(spans, span{From: sp.From, To: from.AddDate(0, 0, -1), FetchedAt: sp.FetchedAt})
+		}
+		if sp.To.After(to) {
+			spans = append(spans, span{From: to.AddDate(0, 0, 1), To: sp.To, FetchedAt: sp.FetchedAt})
+		}
+	}
+	spans = append(spans, span{From: from, To: to, FetchedAt: fetchedAt})
+	sort.Slice(spans, func(i, j int) bool { return spans[i].From.Before(spans[j].From) })
+
+	// Merge neighbouring settled spans so the list stays short; keeping the
+	// older fetch time only ever makes the TTL stricter
+	f.Spans = spans[:0]
+	for _, sp := range spans {
+		n := len(f.Spans)
+		if n > 0 {
+			last := &f.Spans[n-1]
+			if last.To.AddDate(0, 0, 1).Equal(sp.From) && last.settled() && sp.settled() {
+				last.To = sp.To
+				last.FetchedAt = minTime(last.FetchedAt, sp.FetchedAt)
+				continue
+			}
+		}
+		f.Spans = append(f.Spans, sp)
+	}
+}
+
+// between returns the cached entries overlapping [start, end], in
+// chronological order like api.Client.Entries
+func (f *entriesFile) between(start, end time.Time) []api.TimeEntry {
+	var out []api.TimeEntry
+	seen := make(map[int]bool)
+	for d := day(start).AddDate(0, 0, -1); !d.After(day(end)); d = d.AddDate(0, 0, 1) {
+		for _, e := range f.Days[dayKey(d)] {
+			// An entry can be filed under two days by runs fetched apart;
+			// without an ID there's no telling, so those are all kept
+			if seen[e.ID] || !overlaps(e, start, end) {
+				continue
+			}
+			if e.ID != 0 {
+				seen[e.ID] = true
+			}
+			out = append(out, e)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		ti, tj := out[i].Time(), out[j].Time()
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// overlaps mirrors Paymo's time_interval filter: timer entries match if any
+// part of them falls into the window, date-only entries if their day does
+func overlaps(e api.TimeEntry, start, end time.Time) bool {
+	t := e.Time()
+	if e.StartTime == nil {
+		return !t.After(end) && t.AddDate(0, 0, 1).After(start)
+	}
+	return !t.After(end) && !t.Add(time.Duration(e.Duration*float64(time.Second))).Before(start)
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
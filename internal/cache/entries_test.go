@@ -0,0 +1,207 @@
+package cache
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+)
+
+func date(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		d, err = time.Parse("2006-01-02", s)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// timer is a timer entry of the given hours, manual a date-only one
+func timer(t *testing.T, id int, start string, hours float64) api.TimeEntry {
+	ts := api.UnixTS(date(t, start).Unix())
+	return api.TimeEntry{ID: id, StartTime: &ts, Duration: hours * 3600}
+}
+
+func manual(t *testing.T, id int, day string) api.TimeEntry {
+	ts := api.UnixTS(date(t, day).Unix())
+	return api.TimeEntry{ID: id, Date: &ts}
+}
+
+func ids(entries []api.TimeEntry) []int {
+	out := make([]int, len(entries))
+	for i, e := range entries {
+		out[i] = e.ID
+	}
+	return out
+}
+
+func TestRecord(t *testing.T) {
+	f := &entriesFile{}
+	fetched := date(t, "2026-03-05")
+	f.record(date(t, "2026-03-02"), date(t, "2026-03-04"), fetched, []api.TimeEntry{
+		timer(t, 1, "2026-03-02 10:00", 1),
+		timer(t, 2, "2026-03-03 23:30", 2), // runs into the next day
+		timer(t, 3, "2026-03-01 23:00", 2), // starts the day before the run
+		manual(t, 4, "2026-03-04"),
+		{ID: 5}, // no timestamp
+	})
+	want := map[string][]int{"2026-03-02": {1, 3, 5}, "2026-03-03": {2}, "2026-03-04": {4}}
+	for day, w := range want {
+		if got := ids(f.Days[day]); !slices.Equal(got, w) {
+			t.Errorf("%s holds %v, want %v", day, got, w)
+		}
+	}
+	if len(f.Days) != len(want) {
+		t.Errorf("entries filed under %d days, want %d", len(f.Days), len(want))
+	}
+
+	// Refetching a day replaces it and cuts it out of the older span
+	f.record(date(t, "2026-03-03"), date(t, "2026-03-03"), fetched.Add(time.Hour), nil)
+	if _, ok := f.Days["2026-03-03"]; ok {
+		t.Errorf("2026-03-03 still holds %v after an empty refetch", ids(f.Days["2026-03-03"]))
+	}
+	if got := ids(f.Days["2026-03-02"]); !slices.Equal(got, want["2026-03-02"]) {
+		t.Errorf("2026-03-02 holds %v after refetching the next day, want %v", got, want["2026-03-02"])
+	}
+	wantSpans := []span{
+		{From: date(t, "2026-03-02"), To: date(t, "2026-03-02"), FetchedAt: fetched},
+		{From: date(t, "2026-03-03"), To: date(t, "2026-03-03"), FetchedAt: fetched.Add(time.Hour)},
+		{From: date(t, "2026-03-04"), To: date(t, "2026-03-04"), FetchedAt: fetched},
+	}
+	if !slices.Equal(f.Spans, wantSpans) {
+		t.Errorf("spans %v, want %v", f.Spans, wantSpans)
+	}
+}
+
+func TestRecordSpans(t *testing.T) {
+	sp := func(from, to, fetched string) span {
+		return span{From: date(t, from), To: date(t, to), FetchedAt: date(t, fetched)}
+	}
+	tests := []struct {
+		name  string
+		spans []span
+		run   span // recorded last
+		want  []span
+	}{
+		{
+			name:  "settled neighbours merge, keeping the older fetch",
+			spans: []span{sp("2026-03-01", "2026-03-03", "2026-06-01")},
+			run:   sp("2026-03-04", "2026-03-06", "2026-06-02"),
+			want:  []span{sp("2026-03-01", "2026-03-06", "2026-06-01")},
+		},
+		{
+			name:  "recent neighbours stay apart",
+			spans: []span{sp("2026-03-01", "2026-03-03", "2026-03-05")},
+			run:   sp("2026-03-04", "2026-03-04", "2026-03-05"),
+			want:  []span{sp("2026-03-01", "2026-03-03", "2026-03-05"), sp("2026-03-04", "2026-03-04", "2026-03-05")},
+		},
+		{
+			name:  "a run inside a span splits it",
+			spans: []span{sp("2026-03-01", "2026-03-10", "2026-03-11")},
+			run:   sp("2026-03-04", "2026-03-05", "2026-03-12"),
+			want: []span{
+				sp("2026-03-01", "2026-03-03", "2026-03-11"),
+				sp("2026-03-04", "2026-03-05", "2026-03-12"),
+				sp("2026-03-06", "2026-03-10", "2026-03-11"),
+			},
+		},
+		{
+			name:  "an overlapping run replaces the overlap, then merges",
+			spans: []span{sp("2026-03-01", "2026-03-05", "2026-06-01")},
+			run:   sp("2026-03-03", "2026-03-08", "2026-06-02"),
+			want:  []span{sp("2026-03-01", "2026-03-08", "2026-06-01")},
+		},
+		{
+			name:  "a run covering a span drops it",
+			spans: []span{sp("2026-03-03", "2026-03-04", "2026-03-05"), sp("2026-03-10", "2026-03-10", "2026-03-11")},
+			run:   sp("2026-03-01", "2026-03-06", "2026-03-12"),
+			want:  []span{sp("2026-03-01", "2026-03-06", "2026-03-12"), sp("2026-03-10", "2026-03-10", "2026-03-11")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &entriesFile{Spans: slices.Clone(tt.spans)}
+			f.record(tt.run.From, tt.run.To, tt.run.FetchedAt, nil)
+			if !slices.Equal(f.Spans, tt.want) {
+				t.Errorf("spans %v, want %v", f.Spans, tt.want)
+			}
+		})
+	}
+}
+
+func TestStaleRuns(t *testing.T) {
+	now := date(t, "2026-03-20 12:00")
+	f := &entriesFile{Spans: []span{
+		// Settled, but past settledTTL
+		{From: date(t, "2026-01-01"), To: date(t, "2026-01-31"), FetchedAt: date(t, "2026-02-15")},
+		// Settled and within settledTTL
+		{From: date(t, "2026-02-01"), To: date(t, "2026-02-28"), FetchedAt: date(t, "2026-03-15")},
+		// Two and a half hours ago: its first days had settled, the rest is past recentTTL
+		{From: date(t, "2026-03-01"), To: date(t, "2026-03-15"), FetchedAt: date(t, "2026-03-20 09:30")},
+		// 2026-03-16 and 17 were never fetched; the rest is recent enough
+		{From: date(t, "2026-03-18"), To: date(t, "2026-03-20"), FetchedAt: date(t, "2026-03-20 11:30")},
+	}}
+	tests := []struct {
+		name     string
+		from, to string
+		want     []span
+	}{
+		{"everything", "2026-01-01", "2026-03-20", []span{
+			{From: date(t, "2026-01-01"), To: date(t, "2026-01-31")},
+			{From: date(t, "2026-03-13"), To: date(t, "2026-03-17")},
+		}},
+		{"fresh days only", "2026-02-01", "2026-03-12", nil},
+		{"recent days only", "2026-03-18", "2026-03-20", nil},
+		{"a stale day", "2026-03-14", "2026-03-14", []span{{From: date(t, "2026-03-14"), To: date(t, "2026-03-14")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.staleRuns(date(t, tt.from), date(t, tt.to), now); !slices.Equal(got, tt.want) {
+				t.Errorf("stale runs %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBetween(t *testing.T) {
+	straddling := timer(t, 3, "2026-03-02 23:00", 2)
+	f := &entriesFile{Days: map[string][]api.TimeEntry{
+		"2026-03-02": {
+			timer(t, 1, "2026-03-02 10:00", 1),
+			timer(t, 0, "2026-03-02 12:00", 1), // without IDs
+			timer(t, 0, "2026-03-02 11:00", 1),
+			straddling,
+		},
+		// Filed again by a run fetched separately
+		"2026-03-03": {straddling, manual(t, 4, "2026-03-03")},
+		"2026-03-05": {timer(t, 5, "2026-03-05 09:00", 1)},
+	}}
+	tests := []struct {
+		name       string
+		start, end string
+		want       []int
+	}{
+		{"one day", "2026-03-02 00:00", "2026-03-02 23:59", []int{1, 0, 0, 3}},
+		{"the next day", "2026-03-03 00:00", "2026-03-03 23:59", []int{3, 4}},
+		{"after the straddling entry ended", "2026-03-03 02:00", "2026-03-03 23:59", []int{4}},
+		{"all days", "2026-03-02 00:00", "2026-03-05 23:59", []int{1, 0, 0, 3, 4, 5}},
+		{"an empty day", "2026-03-04 00:00", "2026-03-04 23:59", []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := f.between(date(t, tt.start), date(t, tt.end))
+			if !slices.Equal(ids(got), tt.want) {
+				t.Errorf("got entries %v, want %v", ids(got), tt.want)
+			}
+			for i := 1; i < len(got); i++ {
+				if got[i].Time().Before(got[i-1].Time()) {
+					t.Errorf("entry at %s comes after %s", got[i].Time(), got[i-1].Time())
+				}
+			}
+		})
+	}
+}
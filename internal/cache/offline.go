@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+)
+
+// Offline serves reports purely from the store, never touching the network.
+// It has the same methods as api.Client
+type Offline struct {
+	store  *Store
+	userID int
+
+	asOf  time.Time // age of the data behind the last Entries call
+	until time.Time // end of the window the last Entries call served
+}
+
+// NewOffline reads the data cached for the user last seen online
+func NewOffline(store *Store) (*Offline, int, error) {
+	userID, err := store.Me()
+	if err != nil {
+		return nil, 0, err
+	}
+	return &Offline{store: store, userID: userID}, userID, nil
+}
+
+// Entries returns cached entries overlapping [start, end]. Windows reaching
+// past the last sync end there, since nothing newer can be known offline.
+// Any day of the window that was never fetched is an error
+func (o *Offline) Entries(_ context.Context, userID int, start, end time.Time) ([]api.TimeEntry, error) {
+	var f entriesFile
+	if !o.store.load(userID, "entries", &f) || f.Version != schemaVersion || len(f.Spans) == 0 {
+		return nil, ErrNoData
+	}
+
+	var synced time.Time
+	for _, sp := range f.Spans {
+		if sp.FetchedAt.After(synced) {
+			synced = sp.FetchedAt
+		}
+	}
+	if end.After(synced) {
+		end = synced
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("offline data ends %s, before the requested range starts", synced.Format("2006-01-02 15:04"))
+	}
+
+	if missing := f.missingRuns(day(start), day(end)); len(missing) > 0 {
+		parts := make([]string, 0, len(missing))
+		for _, r := range missing {
+			parts = append(parts, fmt.Sprintf("%s to %s", dayKey(r.From), dayKey(r.To)))
+		}
+		return nil, fmt.Errorf("offline data doesn't cover %s; run this report online once to cache it",
+			strings.Join(parts, ", "))
+	}
+
+	o.until = end
+
+	// The report is only as fresh as the oldest fetch it relies on
+	o.asOf = time.Time{}
+	for d := day(start); !d.After(day(end)); d = d.AddDate(0, 0, 1) {
+		if sp := f.covering(d); o.asOf.IsZero() || sp.FetchedAt.Before(o.asOf) {
+			o.asOf = sp.FetchedAt
+		}
+	}
+	return f.between(start, end), nil
+}
+
+// Projects returns the cached projects regardless of their age
+func (o *Offline) Projects(context.Context) (map[int]api.Project, error) {
+	return offlineList[map[int]api.Project](o, "projects")
+}
+
+// Clients returns the cached clients regardless of their age
+func (o *Offline) Clients(context.Context) (map[int]api.Customer, error) {
+	return offlineList[map[int]api.Customer](o, "clients")
+}
+
+// Tasks returns the cached tasks regardless of their age
+func (o *Offline) Tasks(context.Context) (map[int]api.Task, error) {
+	return offlineList[map[int]api.Task](o, "tasks")
+}
+
+// TaskLists returns the cached tasklists regardless of their age
+func (o *Offline) TaskLists(context.Context) (map[int]api.TaskList, error) {
+	return offlineList[map[int]api.TaskList](o, "tasklists")
+}
+
+// Users returns the cached users regardless of their age
+func (o *Offline) Users(context.Context) (map[int]api.User, error) {
+	return offlineList[map[int]api.User](o, "users")
+}
+
+func offlineList[T any](o *Offline, name string) (T, error) {
+	var l list[T]
+	if !o.store.load(o.userID, name, &l) || l.Version != schemaVersion {
+		return l.Items, fmt.Errorf("%s: %w", name, ErrNoData)
+	}
+	return l.Items, nil
+}
+
+// AsOf returns when the data behind the last Entries call was fetched
+func (o *Offline) AsOf() time.Time {
+	return o.asOf
+}
+
+// Until returns where the last Entries call's window ended, which is the
+// last sync if the requested end was later
+func (o *Offline) Until() time.Time {
+	return o.until
+}
@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+)
+
+// Projects, clients etc. rarely change, but new ones should show up the same day
+const listTTL = time.Hour
+
+// Source serves entries and projects from the store, fetching only stale or
+// missing days from Paymo. It has the same methods as api.Client
+type Source struct {
+	client *api.Client
+	store  *Store
+	userID int // owner of cached collections like projects
+}
+
+// NewSource caches data fetched by client for the given (logged in) user
+func NewSource(client *api.Client, store *Store, userID int) *Source {
+	return &Source{client: client, store: store, userID: userID}
+}
+
+// Entries returns entries overlapping [start, end], fetching stale days first
+func (s *Source) Entries(ctx context.Context, userID int, start, end time.Time) ([]api.TimeEntry, error) {
+	f := s.entries(userID)
+
+	// One extra day back catches entries that started before start but overlap it
+	now := s.store.now()
+	runs := f.staleRuns(day(start).AddDate(0, 0, -1), day(end), now)
+	for _, r := range runs {
+		entries, err := s.client.Entries(ctx, userID, r.From, r.To.AddDate(0, 0, 1).Add(-time.Second))
+		if err != nil {
+			return nil, err
+		}
+		f.record(r.From, r.To, now, entries)
+	}
+
+	// The cache is best effort: failing to write it must not fail the report
+	if len(runs) > 0 {
+		_ = s.store.save(userID, "entries", &f)
+	}
+	return f.between(start, end), nil
+}
+
+// FilteredEntries is Entries for a filtered report. If the cache holds
+// every day of [start, end], entries come from it unfiltered and callers
+// filter them locally. Otherwise Paymo filters the whole range, and as the
+// cache keeps whole days only, what it sends isn't cached
+func (s *Source) FilteredEntries(ctx context.Context, userID int, start, end time.Time, filter api.EntryFilter) ([]api.TimeEntry, error) {
+	f := s.entries(userID)
+	if len(f.staleRuns(day(start).AddDate(0, 0, -1), day(end), s.store.now())) == 0 {
+		return f.between(start, end), nil
+	}
+	return s.client.FilteredEntries(ctx, userID, start, end, filter)
+}
+
+// entries loads the user's cached entries, empty if there are none yet or
+// they were cached by another schema version
+func (s *Source) entries(userID int) entriesFile {
+	var f entriesFile
+	if !s.store.load(userID, "entries", &f) || f.Version != schemaVersion {
+		f = entriesFile{Version: schemaVersion}
+	}
+	return f
+}
+
+// Projects returns the projects, refetched once older than listTTL
+func (s *Source) Projects(ctx context.Context) (map[int]api.Project, error) {
+	return cachedList(ctx, s, "projects", s.client.Projects)
+}
+
+// Clients returns the clients, refetched once older than listTTL
+func (s *Source) Clients(ctx context.Context) (map[int]api.Customer, error) {
+	return cachedList(ctx, s, "clients", s.client.Clients)
+}
+
+// cachedList serves a collection from the store while it's younger than
+// listTTL, otherwise fetches and stores it
+func cachedList[T any](ctx context.Context, s *Source, name string, fetch func(context.Context) (T, error)) (T, error) {
+	if items, ok := loadList[T](s.store, s.userID, name, listTTL); ok {
+		return items, nil
+	}
+	items, err := fetch(ctx)
+	if err != nil {
+		return items, err
+	}
+	_ = saveList(s.store, s.userID, name, items)
+	return items, nil
+}
+
+// Tasks returns the tasks, refetched once older than listTTL
+func (s *Source) Tasks(ctx context.Context) (map[int]api.Task, error) {
+	return cachedList(ctx, s, "tasks", s.client.Tasks)
+}
+
+// Users returns the team's users, refetched once older than listTTL
+func (s *Source) Users(ctx context.Context) (map[int]api.User, error) {
+	return cachedList(ctx, s, "users", s.client.Users)
+}
+
+// TaskLists returns the tasklists, refetched once older than listTTL
+func (s *Source) TaskLists(ctx context.Context) (map[int]api.TaskList, error) {
+	return cachedList(ctx, s, "tasklists", s.client.TaskLists)
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+	"github.com/Ma-Kas/paymostats/internal/api/paymotest"
+)
+
+func TestFilteredEntries(t *testing.T) {
+	now := time.Date(2026, 3, 20, 18, 0, 0, 0, time.UTC)
+	fixtures := paymotest.Demo(now)
+	fake := &paymotest.Fake{Fixtures: fixtures}
+	srv := paymotest.NewServer(fake)
+	defer srv.Close()
+
+	store := &Store{dir: t.TempDir(), now: func() time.Time { return now }}
+	src := NewSource(api.NewClient("demo", api.WithBaseURL(srv.URL)), store, 1)
+	ctx := context.Background()
+	start, end := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 6, 23, 59, 59, 0, time.UTC)
+	projects := func(entries []api.TimeEntry) []int {
+		var ids []int
+		for _, e := range entries {
+			if !slices.Contains(ids, e.ProjectID) {
+				ids = append(ids, e.ProjectID)
+			}
+		}
+		slices.Sort(ids)
+		return ids
+	}
+	filter := api.EntryFilter{ProjectIDs: []int{102}}
+
+	// Nothing cached yet: Paymo filters, and the cache stays empty
+	entries, err := src.FilteredEntries(ctx, 1, start, end, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := projects(entries); !slices.Equal(got, []int{102}) {
+		t.Errorf("fetched entries of projects %v, want only 102", got)
+	}
+	if f := src.entries(1); len(f.Days) > 0 || len(f.Spans) > 0 {
+		t.Errorf("filtered entries were cached: %d days, spans %v", len(f.Days), f.Spans)
+	}
+
+	all, err := src.Entries(ctx, 1, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects(all)) < 2 {
+		t.Fatalf("entries of projects %v, the fixtures should cover more than one", projects(all))
+	}
+
+	// Everything cached: served from the cache without a request, unfiltered
+	requests := fake.Requests()
+	cached, err := src.FilteredEntries(ctx, 1, start, end, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fake.Requests() != requests {
+		t.Errorf("%d requests for cached days", fake.Requests()-requests)
+	}
+	if !slices.Equal(projects(cached), projects(all)) {
+		t.Errorf("cached entries of projects %v, want all of %v", projects(cached), projects(all))
+	}
+
+	// A day missing from the cache has Paymo filter the whole range again
+	entries, err = src.FilteredEntries(ctx, 1, start, end.AddDate(0, 0, 1), filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fake.Requests() == requests {
+		t.Error("no request for a day missing from the cache")
+	}
+	if got := projects(entries); !slices.Equal(got, []int{102}) {
+		t.Errorf("fetched entries of projects %v, want only 102", got)
+	}
+}
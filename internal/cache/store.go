@@ -0,0 +1,145 @@
+// Package cache keeps Paymo entries and projects on disk, so repeated reports
+// only fetch what may have changed since the last sync
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoData means nothing usable is cached yet (e.g. offline before any online run)
+var ErrNoData = errors.New("no cached data, run paymostats online once first")
+
+// Store is the on-disk cache for one Paymo API (production, or e.g. a local
+// mock), laid out as <dir>/<userID>/<name>.json
+type Store struct {
+	dir string
+	now func() time.Time
+}
+
+// Root returns the cache root under the user cache dir (shared by all APIs)
+func Root() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "paymostats"), nil
+}
+
+// Open returns the store for the API at apiURL; empty means production Paymo
+func Open(apiURL string) (*Store, error) {
+	root, err := Root()
+	if err != nil {
+		return nil, err
+	}
+	host := "app.paymoapp.com"
+	if apiURL != "" {
+		u, err := url.Parse(apiURL)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid API URL %q", apiURL)
+		}
+		host = u.Host
+	}
+	// Keep the directory name portable (ports contain ':')
+	host = strings.NewReplacer(":", "_", "/", "_").Replace(host)
+	return &Store{dir: filepath.Join(root, host), now: time.Now}, nil
+}
+
+// Clear removes everything cached for every API
+func Clear() error {
+	root, err := Root()
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(root)
+}
+
+func (s *Store) path(userID int, name string) string {
+	return filepath.Join(s.dir, strconv.Itoa(userID), name+".json")
+}
+
+// load reads a cached file into v. ok is false if it doesn't exist or is
+// unreadable (a corrupt cache is treated as empty, never as fatal)
+func (s *Store) load(userID int, name string, v any) (ok bool) {
+	b, err := os.ReadFile(s.path(userID, name))
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(b, v) == nil
+}
+
+// save writes v atomically (temp file + rename)
+func (s *Store) save(userID int, name string, v any) error {
+	p := s.path(userID, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), "."+name+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+// schemaVersion is stored with cached API data. Bump it whenever the cached
+// api types gain fields, so data cached without them is refetched
+const schemaVersion = 5
+
+// list is a cached API collection (projects, ...) with its fetch time
+type list[T any] struct {
+	Version   int       `json:"version"`
+	FetchedAt time.Time `json:"fetched_at"`
+	Items     T         `json:"items"`
+}
+
+// loadList returns a cached collection if it's younger than ttl
+func loadList[T any](s *Store, userID int, name string, ttl time.Duration) (T, bool) {
+	var l list[T]
+	if !s.load(userID, name, &l) || l.Version != schemaVersion || s.now().Sub(l.FetchedAt) >= ttl {
+		var zero T
+		return zero, false
+	}
+	return l.Items, true
+}
+
+func saveList[T any](s *Store, userID int, name string, items T) error {
+	return s.save(userID, name, list[T]{Version: schemaVersion, FetchedAt: s.now(), Items: items})
+}
+
+// me is the last user seen online, so offline mode knows whose data to read
+type me struct {
+	UserID int `json:"user_id"`
+}
+
+// SaveMe remembers the logged in user for offline use
+func (s *Store) SaveMe(userID int) error {
+	return s.save(0, "me", me{UserID: userID})
+}
+
+// Me returns the user last seen online
+func (s *Store) Me() (int, error) {
+	var m me
+	if !s.load(0, "me", &m) || m.UserID == 0 {
+		return 0, ErrNoData
+	}
+	return m.UserID, nil
+}
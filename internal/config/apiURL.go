@@ -0,0 +1,12 @@
+package config
+
+import "os"
+
+// ResolveApiURL returns the Paymo API root to talk to: the flag value if set,
+// then PAYMOSTATS_API_URL. Empty means the client default (production Paymo)
+func ResolveApiURL(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	return os.Getenv("PAYMOSTATS_API_URL")
+}
@@ -1 +1,61 @@
 package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Config is the optional settings file, see Path. Everything in it can be
+// left out
+type Config struct {
+	Utilization Utilization `json:"utilization"`
+}
+
+// Utilization holds the working-hours targets the utilization report
+// measures against
+type Utilization struct {
+	WeeklyHours float64 `json:"weekly_hours"` // spread evenly over the workdays
+	DailyHours  float64 `json:"daily_hours"`  // per workday; wins over weekly_hours
+	// Workdays are weekday names, e.g. "mon" or "Monday"; Monday to Friday if empty
+	Workdays []string `json:"workdays"`
+	// Holidays are days off: YYYY-MM-DD, or MM-DD for every year
+	Holidays     []string `json:"holidays"`
+	BillableOnly bool     `json:"billable_only"` // only billable hours count towards the target
+}
+
+// Path returns where the config file lives: PAYMOSTATS_CONFIG if set,
+// otherwise paymostats/config.json under the user config dir
+func Path() (string, error) {
+	if v := os.Getenv("PAYMOSTATS_CONFIG"); v != "" {
+		return v, nil
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "paymostats", "config.json"), nil
+}
+
+// Load reads the config file. A missing file is an empty config
+func Load() (Config, error) {
+	var c Config
+	path, err := Path()
+	if err != nil {
+		return c, err
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return c, nil
+}
@@ -0,0 +1,117 @@
+package query
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+// Match tells whether an entry passes the expression. The lookup resolves
+// project, client and task names and whether the entry is billable
+func (q *Query) Match(e api.TimeEntry, lk report.Lookup) bool {
+	return eval(q.root, e, lk)
+}
+
+func eval(n node, e api.TimeEntry, lk report.Lookup) bool {
+	switch n := n.(type) {
+	case andNode:
+		return eval(n.left, e, lk) && eval(n.right, e, lk)
+	case orNode:
+		return eval(n.left, e, lk) || eval(n.right, e, lk)
+	case notNode:
+		return !eval(n.x, e, lk)
+	case cmpNode:
+		return n.match(e, lk) != n.negate
+	}
+	return false
+}
+
+// match evaluates the comparison without its negation
+func (c cmpNode) match(e api.TimeEntry, lk report.Lookup) bool {
+	project := lk.Projects[e.ProjectID]
+	switch c.field {
+	case "project":
+		return c.matchString(project.Name)
+	case "client":
+		return c.matchString(lk.Clients[project.ClientID].Name)
+	case "task":
+		return c.matchString(lk.Tasks[e.TaskID].Name)
+	case "description":
+		return c.matchString(e.Description)
+	case "tag":
+		for _, tag := range e.Tags {
+			if c.matchString(tag) {
+				return true
+			}
+		}
+		return false
+	case "project_id":
+		return c.matchNumber(float64(e.ProjectID))
+	case "client_id":
+		return c.matchNumber(float64(project.ClientID))
+	case "task_id":
+		return c.matchNumber(float64(e.TaskID))
+	case "user_id":
+		return c.matchNumber(float64(e.UserID))
+	case "hours":
+		return c.matchNumber(e.Duration / 3600)
+	case "billable":
+		return lk.IsBillable(e) == c.values[0].b
+	case "date":
+		t := e.Time()
+		return c.matchDate(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC))
+	}
+	return false
+}
+
+// matchString compares case insensitively; "=" and "in" need the whole
+// value, "~" a glob or regex match
+func (c cmpNode) matchString(s string) bool {
+	lower := strings.ToLower(s)
+	for _, v := range c.values {
+		switch {
+		case v.re != nil:
+			if v.re.MatchString(s) {
+				return true
+			}
+		case lower == v.str:
+			return true
+		}
+	}
+	return false
+}
+
+func (c cmpNode) matchNumber(n float64) bool {
+	for _, v := range c.values {
+		if compare(c.op, n, v.num) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c cmpNode) matchDate(d time.Time) bool {
+	for _, v := range c.values {
+		if compare(c.op, d.Compare(v.date), 0) {
+			return true
+		}
+	}
+	return false
+}
+
+func compare[T int | float64](op string, a, b T) bool {
+	switch op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	default: // = and in
+		return a == b
+	}
+}
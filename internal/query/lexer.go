@@ -0,0 +1,164 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokRegex
+	tokOp // = != < <= > >= ~ !~
+	tokLParen
+	tokRParen
+	tokComma
+	tokAnd
+	tokOr
+	tokNot
+	tokIn
+	tokTrue
+	tokFalse
+)
+
+var keywords = map[string]tokenKind{
+	"and": tokAnd, "or": tokOr, "not": tokNot, "in": tokIn, "true": tokTrue, "false": tokFalse,
+}
+
+type token struct {
+	kind tokenKind
+	text string // the unquoted value for strings and regexes, the source otherwise
+	pos  int    // 1-based column of the first character
+}
+
+// describe names a token for error messages
+func (t token) describe() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return `"` + t.text + `"`
+	case tokRegex:
+		return "/" + t.text + "/"
+	case tokNumber:
+		return t.text
+	default:
+		return `"` + t.text + `"`
+	}
+}
+
+// lex splits src into tokens, ending with tokEOF
+func lex(src string) ([]token, error) {
+	runes := []rune(src)
+	var tokens []token
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '_' || unicode.IsLetter(r):
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			text := string(runes[start:i])
+			kind, ok := keywords[strings.ToLower(text)]
+			if !ok {
+				kind = tokIdent
+			}
+			tokens = append(tokens, token{kind, text, start + 1})
+			continue
+		case unicode.IsDigit(r) || r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			dot := false
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' && !dot) {
+				dot = dot || runes[i] == '.'
+				i++
+			}
+			tokens = append(tokens, token{tokNumber, string(runes[start:i]), start + 1})
+			continue
+		case r == '"' || r == '\'' || r == '/':
+			text, end, ok := quoted(runes, i)
+			if !ok {
+				what := "string"
+				if r == '/' {
+					what = "regular expression"
+				}
+				return nil, &Error{Src: src, Pos: start + 1, Msg: "unterminated " + what}
+			}
+			kind := tokString
+			if r == '/' {
+				kind = tokRegex
+			}
+			tokens = append(tokens, token{kind, text, start + 1})
+			i = end
+			continue
+		case r == '(':
+			tokens = append(tokens, token{tokLParen, "(", start + 1})
+		case r == ')':
+			tokens = append(tokens, token{tokRParen, ")", start + 1})
+		case r == ',':
+			tokens = append(tokens, token{tokComma, ",", start + 1})
+		case r == '~':
+			tokens = append(tokens, token{tokOp, "~", start + 1})
+		case r == '=':
+			// == is accepted as =
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				i++
+			}
+			tokens = append(tokens, token{tokOp, "=", start + 1})
+		case r == '!' || r == '<' || r == '>':
+			next := rune(0)
+			if i+1 < len(runes) {
+				next = runes[i+1]
+			}
+			op := string(r)
+			switch {
+			case next == '=' || r == '!' && next == '~':
+				op += string(next)
+				i++
+			case r == '!':
+				return nil, &Error{Src: src, Pos: start + 1, Msg: `unexpected "!", use "not", "!=" or "!~"`}
+			}
+			tokens = append(tokens, token{tokOp, op, start + 1})
+		default:
+			return nil, &Error{Src: src, Pos: start + 1, Msg: "unexpected character " + quote(string(r))}
+		}
+		i++
+	}
+	return append(tokens, token{tokEOF, "", len(runes) + 1}), nil
+}
+
+// quoted reads the string or regex starting with the delimiter at runes[i].
+// A backslash escapes the delimiter, and in strings itself; other escapes
+// are kept as they are (for the regexp package). It returns the text and
+// the index after the closing delimiter
+func quoted(runes []rune, i int) (string, int, bool) {
+	delim := runes[i]
+	var b strings.Builder
+	for i++; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == delim:
+			return b.String(), i + 1, true
+		case r == '\\' && i+1 < len(runes):
+			next := runes[i+1]
+			unescape := next == delim || next == '\\' && delim != '/'
+			if !unescape {
+				b.WriteRune(r)
+			}
+			b.WriteRune(next)
+			i++
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return "", i, false
+}
+
+func quote(s string) string {
+	return `"` + s + `"`
+}
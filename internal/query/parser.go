@@ -0,0 +1,284 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+// node is a parsed expression: andNode, orNode, notNode or cmpNode
+type node interface{}
+
+type andNode struct{ left, right node }
+
+type orNode struct{ left, right node }
+
+type notNode struct{ x node }
+
+// cmpNode compares an entry field with one or more literals. Negated
+// operators (!=, !~, not in) are stored as their positive form plus negate
+type cmpNode struct {
+	field  string
+	typ    Type
+	op     string // =, <, <=, >, >=, ~ or in
+	negate bool
+	values []literal
+}
+
+// literal is a checked value, converted for the field it's compared with
+type literal struct {
+	str  string         // strings, lower case
+	num  float64        // numbers
+	b    bool           // booleans
+	date time.Time      // dates
+	re   *regexp.Regexp // ~ with a /regex/ or a glob
+	glob string         // ~ with a string: the glob, lower case
+}
+
+type parser struct {
+	src    string
+	tokens []token
+	i      int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) errorf(pos int, format string, args ...any) error {
+	return &Error{Src: p.src, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// parse parses the whole expression:
+//
+//	expr       = and { "or" and }
+//	and        = not { "and" not }
+//	not        = "not" not | primary
+//	primary    = "(" expr ")" | field [ op value | [ "not" ] "in" "(" value { "," value } ")" ]
+func (p *parser) parse() (node, error) {
+	if p.peek().kind == tokEOF {
+		return nil, p.errorf(1, "empty expression")
+	}
+	n, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t.pos, "unexpected %s, expected \"and\" or \"or\"", t.describe())
+	}
+	return n, nil
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr {
+		p.next()
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokAnd {
+		p.next()
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) not() (node, error) {
+	if p.peek().kind == tokNot {
+		p.next()
+		x, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{x}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokLParen:
+		n, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if end := p.next(); end.kind != tokRParen {
+			return nil, p.errorf(end.pos, "expected \")\" to close the \"(\" at column %d, found %s", t.pos, end.describe())
+		}
+		return n, nil
+	case tokIdent:
+		return p.comparison(t)
+	default:
+		return nil, p.errorf(t.pos, "expected a field name, found %s", t.describe())
+	}
+}
+
+// comparison parses what follows a field name and checks it against the field's type
+func (p *parser) comparison(field token) (node, error) {
+	name := strings.ToLower(field.text)
+	typ, ok := Fields[name]
+	if !ok {
+		return nil, p.errorf(field.pos, "unknown field %q (fields: %s)", field.text, fieldList())
+	}
+	c := cmpNode{field: name, typ: typ}
+
+	opTok := p.peek()
+	switch {
+	case opTok.kind == tokOp:
+		p.next()
+		c.op = opTok.text
+		switch c.op {
+		case "!=":
+			c.op, c.negate = "=", true
+		case "!~":
+			c.op, c.negate = "~", true
+		}
+	case opTok.kind == tokIn:
+		p.next()
+		c.op = "in"
+	case opTok.kind == tokNot && p.tokens[p.i+1].kind == tokIn:
+		p.next()
+		p.next()
+		c.op, c.negate = "in", true
+	default:
+		// A boolean field on its own, e.g. "billable"
+		if typ != Bool {
+			return nil, p.errorf(field.pos, "%s is a %s, compare it with %s", name, typ, operators[typ])
+		}
+		c.op, c.values = "=", []literal{{b: true}}
+		return c, nil
+	}
+
+	switch c.op {
+	case "~":
+		if typ != String {
+			return nil, p.errorf(opTok.pos, "%q needs a string field, %s is a %s", opTok.text, name, typ)
+		}
+	case "<", "<=", ">", ">=":
+		if typ != Number && typ != Date {
+			return nil, p.errorf(opTok.pos, "%q needs a number or date field, %s is a %s", opTok.text, name, typ)
+		}
+	case "in":
+		if typ == Bool {
+			return nil, p.errorf(opTok.pos, "\"in\" needs a list of values, %s is a boolean", name)
+		}
+	}
+
+	if c.op != "in" {
+		v, err := p.value(c)
+		if err != nil {
+			return nil, err
+		}
+		c.values = []literal{v}
+		return c, nil
+	}
+
+	if t := p.next(); t.kind != tokLParen {
+		return nil, p.errorf(t.pos, "expected \"(\" to start the list after \"in\", found %s", t.describe())
+	}
+	for {
+		v, err := p.value(c)
+		if err != nil {
+			return nil, err
+		}
+		c.values = append(c.values, v)
+		t := p.next()
+		if t.kind == tokRParen {
+			return c, nil
+		}
+		if t.kind != tokComma {
+			return nil, p.errorf(t.pos, "expected \",\" or \")\" in the list, found %s", t.describe())
+		}
+	}
+}
+
+// operators lists the operators each type takes, for error messages
+var operators = map[Type]string{
+	String: "=, !=, ~, !~ or in",
+	Number: "=, !=, <, <=, >, >= or in",
+	Date:   "=, !=, <, <=, >, >= or in",
+}
+
+// value parses a literal and converts it for the comparison's field and operator
+func (p *parser) value(c cmpNode) (literal, error) {
+	t := p.next()
+	switch c.typ {
+	case String:
+		switch {
+		case t.kind == tokRegex && c.op == "~":
+			re, err := regexp.Compile("(?i)" + t.text)
+			if err != nil {
+				return literal{}, p.errorf(t.pos, "invalid regular expression: %v", err)
+			}
+			return literal{re: re}, nil
+		case t.kind == tokRegex:
+			return literal{}, p.errorf(t.pos, "regular expressions need the ~ operator")
+		case t.kind == tokString && c.op == "~":
+			re, err := report.CompileGlob(t.text)
+			if err != nil {
+				return literal{}, p.errorf(t.pos, "invalid pattern: %v", err)
+			}
+			return literal{re: re, glob: strings.ToLower(t.text)}, nil
+		case t.kind == tokString:
+			return literal{str: strings.ToLower(t.text)}, nil
+		}
+	case Number:
+		if t.kind == tokNumber {
+			n, err := strconv.ParseFloat(t.text, 64)
+			if err != nil {
+				return literal{}, p.errorf(t.pos, "invalid number %s", t.text)
+			}
+			return literal{num: n}, nil
+		}
+	case Bool:
+		if t.kind == tokTrue || t.kind == tokFalse {
+			return literal{b: t.kind == tokTrue}, nil
+		}
+	case Date:
+		if t.kind == tokString {
+			d, err := time.Parse("2006-01-02", t.text)
+			if err != nil {
+				return literal{}, p.errorf(t.pos, "invalid date %q, use YYYY-MM-DD", t.text)
+			}
+			return literal{date: d}, nil
+		}
+	}
+
+	switch t.kind {
+	case tokString, tokNumber, tokRegex, tokTrue, tokFalse:
+		return literal{}, p.errorf(t.pos, "%s is a %s, %s isn't", c.field, c.typ, t.describe())
+	default:
+		return literal{}, p.errorf(t.pos, "expected a %s value for %s, found %s", c.typ, c.field, t.describe())
+	}
+}
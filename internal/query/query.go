@@ -0,0 +1,104 @@
+// Package query implements the --where expression language for filtering
+// time entries, e.g.
+//
+//	project ~ "Acme*" and billable and hours > 0.5
+//
+// Expressions are parsed and type-checked against the entry fields up front,
+// evaluated locally per entry, and the parts Paymo can apply are translated
+// to an api.EntryFilter
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Type is the type of an entry field
+type Type int
+
+const (
+	String Type = iota
+	Number
+	Bool
+	Date
+)
+
+func (t Type) String() string {
+	switch t {
+	case Number:
+		return "number"
+	case Bool:
+		return "boolean"
+	case Date:
+		return "date"
+	default:
+		return "string"
+	}
+}
+
+// Fields are the entry fields an expression can use, with their types
+var Fields = map[string]Type{
+	"project":     String,
+	"project_id":  Number,
+	"client":      String,
+	"client_id":   Number,
+	"task":        String,
+	"task_id":     Number,
+	"user_id":     Number,
+	"description": String,
+	"tag":         String, // matches if any of the entry's tags does
+	"billable":    Bool,
+	"hours":       Number,
+	"date":        Date, // the day of the entry, compared with "YYYY-MM-DD"
+}
+
+func fieldList() string {
+	names := make([]string, 0, len(Fields))
+	for name := range Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// Error is a syntax or type error at a column of the expression
+type Error struct {
+	Src string
+	Pos int // 1-based column
+	Msg string
+}
+
+// Error reports the message with the expression and a caret under the column
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at column %d\n  %s\n  %s^", e.Msg, e.Pos, e.Src, strings.Repeat(" ", e.Pos-1))
+}
+
+// Query is a parsed and type-checked expression
+type Query struct {
+	src  string
+	root node
+}
+
+// Parse parses and type-checks an expression
+func Parse(src string) (*Query, error) {
+	if !utf8.ValidString(src) {
+		return nil, fmt.Errorf("expression is not valid UTF-8")
+	}
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{src: src, tokens: tokens}
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return &Query{src: src, root: root}, nil
+}
+
+// String returns the expression as given
+func (q *Query) String() string {
+	return q.src
+}
@@ -0,0 +1,197 @@
+package query_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+	"github.com/Ma-Kas/paymostats/internal/api/paymotest"
+	"github.com/Ma-Kas/paymostats/internal/query"
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+// lookup has a billable client project and an internal one, with a task each
+var lookup = report.Lookup{
+	Projects: map[int]api.Project{
+		1: {ID: 1, Name: "Acme / Website", ClientID: 11, Billable: true},
+		2: {ID: 2, Name: "Internal", ClientID: 0},
+	},
+	Clients: map[int]api.Customer{11: {ID: 11, Name: "Acme Corp"}},
+	Tasks: map[int]api.Task{
+		10: {ID: 10, Name: "Design", ProjectID: 1},
+		20: {ID: 20, Name: "Standup", ProjectID: 2},
+	},
+}
+
+func TestMatch(t *testing.T) {
+	// billable: Acme / Website, 2 hours, tagged; internal: Internal, half an hour
+	billable := api.TimeEntry{ProjectID: 1, TaskID: 10, Duration: 7200, Tags: []string{"urgent"}, Description: "Landing page"}
+	internal := api.TimeEntry{ProjectID: 2, TaskID: 20, Duration: 1800, Description: "Daily standup"}
+
+	tests := []struct {
+		expr                  string
+		billable, nonBillable bool
+	}{
+		// Precedence: not binds tighter than and, and tighter than or
+		{`billable or hours > 1 and project = "Internal"`, true, false},
+		{`(billable or hours > 1) and project = "Internal"`, false, false},
+		{`project = "Internal" or billable and hours < 1`, false, true},
+		{`not billable and hours < 1`, false, true},
+		{`not (billable and hours < 1)`, true, true},
+		{`not billable or hours > 1`, true, true},
+		{`not not billable`, true, false},
+		{`billable and not hours > 1 or task = "standup"`, false, true},
+
+		// not in and the other negations
+		{`project not in ("Internal", "Other")`, true, false},
+		{`not project in ("Internal", "Other")`, true, false},
+		{`project_id not in (1)`, false, true},
+		{`tag not in ("urgent")`, false, true},
+		{`task != "design"`, false, true},
+		{`description !~ "*standup*"`, true, false},
+
+		// Globs match across slashes, regexes anywhere in the value
+		{`project ~ "acme*"`, true, false},
+		{`project ~ "*/ web*"`, true, false},
+		{`client ~ "acme"`, false, false},
+		{`project ~ /web/`, true, false},
+		{`project ~ "web*"`, false, false},
+		{`description ~ "daily?standup"`, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			q, err := query.Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := q.Match(billable, lookup); got != tt.billable {
+				t.Errorf("billable entry: got %v, want %v", got, tt.billable)
+			}
+			if got := q.Match(internal, lookup); got != tt.nonBillable {
+				t.Errorf("internal entry: got %v, want %v", got, tt.nonBillable)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		pos  int
+		msg  string
+	}{
+		{``, 1, "empty expression"},
+		{`nosuch = 1`, 1, `unknown field "nosuch"`},
+		{`hours`, 1, "hours is a number"},
+		{`hours ~ "1*"`, 7, `"~" needs a string field, hours is a number`},
+		{`project > 1`, 9, `">" needs a number or date field, project is a string`},
+		{`billable in (true)`, 10, `"in" needs a list of values`},
+		{`project = 1`, 11, "project is a string, 1 isn't"},
+		{`hours > "1"`, 9, `hours is a number, "1" isn't`},
+		{`billable = "yes"`, 12, "billable is a boolean"},
+		{`date = "2026-13-01"`, 8, "invalid date"},
+		{`project = /web/`, 11, "regular expressions need the ~ operator"},
+		{`project ~ /(/`, 11, "invalid regular expression"},
+		{`project ~ "[a-"`, 11, "invalid pattern"},
+		{`project = "acme`, 11, "unterminated string"},
+		{`project = 'acme`, 11, "unterminated string"},
+		{`project ~ /acme`, 11, "unterminated regular expression"},
+		{`billable and (hours > 1`, 24, `expected ")" to close the "(" at column 14`},
+		{`project in ("a" "b")`, 17, `expected "," or ")"`},
+		{`billable hours`, 10, `expected "and" or "or"`},
+		{`billable ! hours`, 10, `unexpected "!"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := query.Parse(tt.expr)
+			var qerr *query.Error
+			if !errors.As(err, &qerr) {
+				t.Fatalf("got %v, want a *query.Error", err)
+			}
+			if qerr.Pos != tt.pos {
+				t.Errorf("column %d, want %d: %s", qerr.Pos, tt.pos, qerr.Msg)
+			}
+			if !strings.Contains(qerr.Msg, tt.msg) {
+				t.Errorf("message %q, want it to contain %q", qerr.Msg, tt.msg)
+			}
+		})
+	}
+}
+
+// TestEntryFilterSuperset checks the filter sent to Paymo never drops an
+// entry the expression matches, against the demo data
+func TestEntryFilterSuperset(t *testing.T) {
+	now := time.Date(2026, 3, 20, 18, 0, 0, 0, time.UTC)
+	srv := paymotest.NewServer(&paymotest.Fake{Fixtures: paymotest.Demo(now)})
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := api.NewClient("demo", api.WithBaseURL(srv.URL))
+	entries, err := c.Entries(ctx, 1, now.AddDate(0, -1, 0), now)
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	var lk report.Lookup
+	if lk.Projects, err = c.Projects(ctx); err != nil {
+		t.Fatalf("Projects: %v", err)
+	}
+	if lk.Clients, err = c.Clients(ctx); err != nil {
+		t.Fatalf("Clients: %v", err)
+	}
+	if lk.Tasks, err = c.Tasks(ctx); err != nil {
+		t.Fatalf("Tasks: %v", err)
+	}
+
+	tests := []struct {
+		expr       string
+		pushesDown bool // whether anything reaches Paymo
+	}{
+		{`project ~ "website*"`, true},
+		{`client = "acme corp" and hours > 1`, true},
+		{`client_id in (11, 12) and not billable`, true},
+		{`project_id in (101, 102) and task ~ "*review*"`, true},
+		{`task_id in (10111, 10211)`, true},
+		{`description ~ "*onboarding*" and project ~ /app|web/`, true},
+		{`description = "daily standup"`, true},
+		{`client ~ "acme*" and (billable or hours < 1)`, true},
+		{`task ~ "standup*" or project = "Mobile App"`, false},
+		{`not project = "Internal Tools"`, false},
+		{`project != "Internal Tools" and description ~ "*review"`, false},
+		{`project not in ("Onboarding") and hours > 0.5`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			q, err := query.Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f := q.EntryFilter(lk)
+			if pushed := f.ProjectIDs != nil || f.TaskIDs != nil || f.Description != ""; pushed != tt.pushesDown {
+				t.Errorf("filter %+v, want pushdown %v", f, tt.pushesDown)
+			}
+			matched := 0
+			for _, e := range entries {
+				if !q.Match(e, lk) {
+					continue
+				}
+				matched++
+				if f.ProjectIDs != nil && !slices.Contains(f.ProjectIDs, e.ProjectID) {
+					t.Errorf("filter projects %v drop matching entry %d of project %d", f.ProjectIDs, e.ID, e.ProjectID)
+				}
+				if f.TaskIDs != nil && !slices.Contains(f.TaskIDs, e.TaskID) {
+					t.Errorf("filter tasks %v drop matching entry %d of task %d", f.TaskIDs, e.ID, e.TaskID)
+				}
+				if !strings.Contains(strings.ToLower(e.Description), f.Description) {
+					t.Errorf("filter description %q drops matching entry %d %q", f.Description, e.ID, e.Description)
+				}
+			}
+			if matched == 0 {
+				t.Error("no entry matches, the test checks nothing")
+			}
+		})
+	}
+}
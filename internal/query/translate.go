@@ -0,0 +1,95 @@
+package query
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+// EntryFilter translates what Paymo can apply of the expression: the
+// comparisons joined by top-level "and"s on projects, clients, tasks and
+// descriptions. The filter may let more entries through than the
+// expression, so Match still has to check every entry
+func (q *Query) EntryFilter(lk report.Lookup) api.EntryFilter {
+	var f api.EntryFilter
+	for _, c := range conjuncts(q.root, nil) {
+		f = f.And(c.entryFilter(lk))
+	}
+	return f
+}
+
+// conjuncts returns the comparisons n requires, i.e. those not below an
+// "or" or "not"
+func conjuncts(n node, out []cmpNode) []cmpNode {
+	switch n := n.(type) {
+	case andNode:
+		return conjuncts(n.right, conjuncts(n.left, out))
+	case cmpNode:
+		return append(out, n)
+	}
+	return out
+}
+
+// entryFilter translates a single comparison, or returns the zero filter if
+// Paymo can't apply it
+func (c cmpNode) entryFilter(lk report.Lookup) api.EntryFilter {
+	if c.negate || c.op != "=" && c.op != "in" && c.op != "~" {
+		return api.EntryFilter{}
+	}
+	switch c.field {
+	case "project":
+		return api.EntryFilter{ProjectIDs: matching(lk.Projects, func(p api.Project) bool { return c.matchString(p.Name) })}
+	case "project_id":
+		return api.EntryFilter{ProjectIDs: c.ids()}
+	case "client":
+		return api.EntryFilter{ProjectIDs: matching(lk.Projects, func(p api.Project) bool { return c.matchString(lk.Clients[p.ClientID].Name) })}
+	case "client_id":
+		return api.EntryFilter{ProjectIDs: matching(lk.Projects, func(p api.Project) bool { return c.matchNumber(float64(p.ClientID)) })}
+	case "task":
+		return api.EntryFilter{TaskIDs: matching(lk.Tasks, func(t api.Task) bool { return c.matchString(t.Name) })}
+	case "task_id":
+		return api.EntryFilter{TaskIDs: c.ids()}
+	case "description":
+		if len(c.values) != 1 {
+			return api.EntryFilter{}
+		}
+		// An exact description contains itself; a glob only if it's *text*
+		v := c.values[0]
+		text := v.str
+		if c.op == "~" {
+			text = strings.TrimSuffix(strings.TrimPrefix(v.glob, "*"), "*")
+			if v.glob == "" || len(text)+2 != len(v.glob) || strings.ContainsAny(text, `*?[\`) {
+				return api.EntryFilter{}
+			}
+		}
+		return api.EntryFilter{Description: text}
+	}
+	return api.EntryFilter{}
+}
+
+// ids returns the whole numbers an "=" or "in" comparison lists
+func (c cmpNode) ids() []int {
+	ids := []int{}
+	for _, v := range c.values {
+		if id := int(v.num); float64(id) == v.num {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// matching returns the sorted IDs of the items passing ok, never nil so an
+// expression matching nothing doesn't turn into no filter
+func matching[T any](items map[int]T, ok func(T) bool) []int {
+	ids := []int{}
+	for id, item := range items {
+		if ok(item) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
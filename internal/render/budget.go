@@ -0,0 +1,257 @@
+package render
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+// RenderBudget writes the project budget burn-down to w in the given format
+func RenderBudget(w io.Writer, f Format, b report.Budget) error {
+	switch f {
+	case Table:
+		return budgetTable(w, b)
+	case JSON:
+		return budgetJSON(w, b)
+	case CSV:
+		return budgetDelimited(w, b, ',')
+	case TSV:
+		return budgetDelimited(w, b, '\t')
+	case Markdown:
+		return budgetMarkdown(w, b)
+	case HTML:
+		return budgetHTML(w, b)
+	default:
+		return fmt.Errorf("unknown output format %q", f)
+	}
+}
+
+// budgetCaption is the line under the budget title: the date and the burn rate window
+func budgetCaption(b report.Budget) string {
+	c := fmt.Sprintf("As of %s, burn rate over the last %d days", b.Now.Format("2006-01-02"), int(b.Window.Hours()/24))
+	if !b.AsOf.IsZero() {
+		c += fmt.Sprintf(" (offline, data %s old)", age(time.Since(b.AsOf)))
+	}
+	return c
+}
+
+// exhaustion says when a budget runs out; overruns say when it ran out
+func exhaustion(r report.BudgetRow) string {
+	switch {
+	case r.Exhaustion.IsZero():
+		return "-"
+	case r.Over():
+		return "ran out " + r.Exhaustion.Format("2006-01-02")
+	default:
+		return r.Exhaustion.Format("2006-01-02")
+	}
+}
+
+// budgetCells are the human readable cells of a row: budget, used,
+// remaining, burned, weekly burn rate and exhaustion
+func budgetCells(b report.Budget, r report.BudgetRow) []string {
+	return []string{
+		fmt.Sprintf("%.1f", r.BudgetHours), fmt.Sprintf("%.1f", r.UsedHours), fmt.Sprintf("%.1f", r.Remaining()),
+		fmt.Sprintf("%.1f%%", r.Burned()), fmt.Sprintf("%.1f", weeklyBurn(b, r)), exhaustion(r),
+	}
+}
+
+// weeklyBurn is the row's recent burn rate in hours per week
+func weeklyBurn(b report.Budget, r report.BudgetRow) float64 {
+	if b.Window <= 0 {
+		return 0
+	}
+	return r.RecentHours / b.Window.Hours() * 24 * 7
+}
+
+var budgetHeader = []string{"Project", "Budget", "Used", "Remaining", "Burned", "Hours/week", "Runs out"}
+
+// terminal tells whether w is a terminal, so colors won't end up in files and pipes
+func terminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+func budgetTable(w io.Writer, b report.Budget) error {
+	tw := reportTable(w, strings.ToUpper(b.Label)+"\n"+budgetCaption(b))
+
+	// Overruns in red
+	color := terminal(w)
+	red := func(s string) string {
+		if !color {
+			return s
+		}
+		return text.Colors{text.FgRed}.Sprint(s)
+	}
+	tw.AppendHeader(toTableRow(budgetHeader))
+	for _, r := range b.Rows {
+		rec := append([]string{r.Name}, budgetCells(b, r)...)
+		if r.Over() {
+			for i, v := range rec {
+				rec[i] = red(v)
+			}
+		}
+		tw.AppendRow(toTableRow(rec))
+	}
+	tw.AppendSeparator()
+	total := b.Total()
+	footer := budgetCells(b, total)
+	tw.AppendFooter(toTableRow(append([]string{""}, footer[:len(footer)-1]...)))
+	tw.SetColumnConfigs(rightAligned(len(budgetHeader)))
+
+	tw.Render()
+	return nil
+}
+
+func budgetMarkdown(w io.Writer, b report.Budget) error {
+	markdownHeading(w, b.Label, budgetCaption(b))
+
+	tw := table.NewWriter()
+	tw.AppendHeader(toTableRow(budgetHeader))
+	for _, r := range b.Rows {
+		cells := budgetCells(b, r)
+		if r.Over() {
+			cells[3] = "**" + cells[3] + "**"
+		}
+		tw.AppendRow(toTableRow(append([]string{r.Name}, cells...)))
+	}
+	cells := budgetCells(b, b.Total())
+	tw.AppendFooter(toTableRow(bold(append([]string{"Total"}, append(cells[:len(cells)-1], "")...))))
+	tw.SetColumnConfigs(rightAligned(len(budgetHeader)))
+
+	_, err := fmt.Fprintln(w, tw.RenderMarkdown())
+	return err
+}
+
+type jsonBudget struct {
+	Label          string          `json:"label"`
+	AsOf           string          `json:"as_of"`
+	WindowDays     int             `json:"burn_rate_days"`
+	BudgetHours    float64         `json:"budget_hours"`
+	UsedHours      float64         `json:"used_hours"`
+	RemainingHours float64         `json:"remaining_hours"`
+	BurnedPercent  float64         `json:"burned_percent"`
+	DataAsOf       string          `json:"data_as_of,omitempty"`
+	Projects       []jsonBudgetRow `json:"projects"`
+}
+
+type jsonBudgetRow struct {
+	Name           string  `json:"name"`
+	BudgetHours    float64 `json:"budget_hours"`
+	UsedHours      float64 `json:"used_hours"`
+	RemainingHours float64 `json:"remaining_hours"`
+	BurnedPercent  float64 `json:"burned_percent"`
+	WeeklyHours    float64 `json:"hours_per_week"`
+	Over           bool    `json:"over_budget"`
+	// The date the budget ran out (over budget) or will at the current
+	// rate; null if nothing was burned recently
+	Exhaustion *string `json:"exhaustion_date"`
+}
+
+func budgetJSON(w io.Writer, b report.Budget) error {
+	total := b.Total()
+	out := jsonBudget{
+		Label:          b.Label,
+		AsOf:           b.Now.Format("2006-01-02"),
+		WindowDays:     int(b.Window.Hours() / 24),
+		BudgetHours:    total.BudgetHours,
+		UsedHours:      total.UsedHours,
+		RemainingHours: total.Remaining(),
+		BurnedPercent:  total.Burned(),
+		Projects:       make([]jsonBudgetRow, 0, len(b.Rows)),
+	}
+	for _, r := range b.Rows {
+		row := jsonBudgetRow{
+			Name:           r.Name,
+			BudgetHours:    r.BudgetHours,
+			UsedHours:      r.UsedHours,
+			RemainingHours: r.Remaining(),
+			BurnedPercent:  r.Burned(),
+			WeeklyHours:    weeklyBurn(b, r),
+			Over:           r.Over(),
+		}
+		if !r.Exhaustion.IsZero() {
+			d := r.Exhaustion.Format("2006-01-02")
+			row.Exhaustion = &d
+		}
+		out.Projects = append(out.Projects, row)
+	}
+	if !b.AsOf.IsZero() {
+		out.DataAsOf = b.AsOf.UTC().Format(time.RFC3339)
+	}
+
+	return writeJSON(w, out)
+}
+
+// budgetDelimited writes one record per project plus a final "total"
+// record. exhaustion_date is empty when nothing was burned recently
+func budgetDelimited(w io.Writer, b report.Budget, comma rune) error {
+	asOf := b.Now.Format("2006-01-02")
+	record := func(typ string, r report.BudgetRow) []string {
+		date := ""
+		if !r.Exhaustion.IsZero() {
+			date = r.Exhaustion.Format("2006-01-02")
+		}
+		return []string{asOf, typ, r.Name,
+			number(r.BudgetHours), number(r.UsedHours), number(r.Remaining()), number(r.Burned()),
+			number(weeklyBurn(b, r)), strconv.FormatBool(r.Over()), date}
+	}
+
+	records := [][]string{{
+		"as_of", "type", "name", "budget_hours", "used_hours", "remaining_hours", "burned_percent",
+		"hours_per_week", "over_budget", "exhaustion_date",
+	}}
+	for _, r := range b.Rows {
+		records = append(records, record("project", r))
+	}
+	records = append(records, record("total", b.Total()))
+
+	return writeDelimited(w, comma, records)
+}
+
+// budgetHTML writes a page with a burn bar per project, overruns in red
+func budgetHTML(w io.Writer, b report.Budget) error {
+	p := page{
+		Title:   b.Label,
+		Heading: b.Label,
+		Caption: []string{budgetCaption(b)},
+		Style: `  table { min-width: 40rem; }
+  tr.over td { color: #cf222e; }
+  tr.over td.bar div { background: #cf222e; }
+`,
+		Head: [][]pageCell{headCells("Project", "Budget", "Used", "Remaining", "Burned", "", "Hours/week", "Runs out")},
+	}
+	// cells are a row's numbers with the burn bar after the burned share;
+	// the bar and the exhaustion date are left out of the total
+	cells := func(r report.BudgetRow, total bool) []pageCell {
+		c := budgetCells(b, r)
+		out := []pageCell{num(c[0]), num(c[1]), num(c[2]), num(c[3]), {}, num(c[4]), {}}
+		if !total {
+			out[4] = pageCell{Class: "bar", Bar: template.CSS(fmt.Sprintf("%.1f%%", min(r.Burned(), 100)))}
+			out[6] = num(c[5])
+		}
+		return out
+	}
+	for _, r := range b.Rows {
+		row := pageRow{Cells: append([]pageCell{{Text: r.Name}}, cells(r, false)...)}
+		if r.Over() {
+			row.Class = "over"
+		}
+		p.Rows = append(p.Rows, row)
+	}
+	p.Foot = append([]pageCell{{Text: "Total"}}, cells(b.Total(), true)...)
+	return writePage(w, p)
+}
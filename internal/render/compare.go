@@ -0,0 +1,243 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+// RenderComparison writes two periods side by side to w in the given format
+func RenderComparison(w io.Writer, f Format, c report.Comparison) error {
+	switch f {
+	case Table:
+		return compareTable(w, c)
+	case JSON:
+		return compareJSON(w, c)
+	case CSV:
+		return compareDelimited(w, c, ',')
+	case TSV:
+		return compareDelimited(w, c, '\t')
+	case Markdown:
+		return compareMarkdown(w, c)
+	case HTML:
+		return compareHTML(w, c)
+	default:
+		return fmt.Errorf("unknown output format %q", f)
+	}
+}
+
+// dates is a summary's range as "YYYY-MM-DD to YYYY-MM-DD"
+func dates(s report.Summary) string {
+	return s.Start.Format("2006-01-02") + " to " + s.End.Format("2006-01-02")
+}
+
+func delta(h float64) string {
+	return fmt.Sprintf("%+.1f", h)
+}
+
+// change formats a relative change; groups without base hours are new
+func change(pct float64, ok bool) string {
+	if !ok {
+		return "new"
+	}
+	return fmt.Sprintf("%+.1f%%", pct)
+}
+
+// compareCells are the human readable cells of a row (or the total):
+// base hours and share, current hours and share, delta and change
+func compareCells(r report.CompareRow) []string {
+	pct, ok := r.Change()
+	return []string{
+		fmt.Sprintf("%.1f", r.BaseHours), fmt.Sprintf("%.1f%%", r.BasePercent),
+		fmt.Sprintf("%.1f", r.Hours), fmt.Sprintf("%.1f%%", r.Percent),
+		delta(r.Delta()), change(pct, ok),
+	}
+}
+
+// totalRow is the comparison's totals in row form
+func totalRow(c report.Comparison) report.CompareRow {
+	return report.CompareRow{
+		Name:        "Total",
+		Hours:       c.Current.TotalHours,
+		Percent:     percentSum(c.Current.Rows),
+		BaseHours:   c.Base.TotalHours,
+		BasePercent: percentSum(c.Base.Rows),
+	}
+}
+
+func compareTable(w io.Writer, c report.Comparison) error {
+	t := fmt.Sprintf("%s vs %s\n%s vs %s", strings.ToUpper(c.Current.Label), strings.ToUpper(c.Base.Label), dates(c.Current), dates(c.Base))
+	if !c.Current.AsOf.IsZero() {
+		t += fmt.Sprintf("\nOffline, data %s old", age(time.Since(c.Current.AsOf)))
+	}
+	tw := reportTable(w, t)
+
+	merged := table.RowConfig{AutoMerge: true}
+	tw.AppendHeader(table.Row{"", c.Base.Label, c.Base.Label, c.Current.Label, c.Current.Label, "Change", "Change"}, merged)
+	tw.AppendHeader(table.Row{groupHeader(c.Current), "Hours", "Share", "Hours", "Share", "Hours", "Percent"})
+	for _, r := range c.Rows {
+		tw.AppendRow(toTableRow(append([]string{r.Name}, compareCells(r)...)))
+	}
+	tw.AppendSeparator()
+	tw.AppendFooter(toTableRow(append([]string{""}, compareCells(totalRow(c))...)))
+	tw.SetColumnConfigs(rightAligned(7))
+
+	tw.Render()
+	return nil
+}
+
+func compareMarkdown(w io.Writer, c report.Comparison) error {
+	markdownHeading(w, c.Current.Label+" vs "+c.Base.Label, dates(c.Current)+" vs "+dates(c.Base)+offline(c.Current.AsOf))
+
+	tw := table.NewWriter()
+	tw.AppendHeader(table.Row{
+		groupHeader(c.Current),
+		c.Base.Label + " hours", c.Base.Label + " share",
+		c.Current.Label + " hours", c.Current.Label + " share",
+		"Change", "Change %",
+	})
+	for _, r := range c.Rows {
+		tw.AppendRow(toTableRow(append([]string{r.Name}, compareCells(r)...)))
+	}
+	tw.AppendFooter(toTableRow(bold(append([]string{"Total"}, compareCells(totalRow(c))...))))
+	tw.SetColumnConfigs(rightAligned(7))
+
+	_, err := fmt.Fprintln(w, tw.RenderMarkdown())
+	return err
+}
+
+type jsonPeriod struct {
+	Label      string  `json:"label"`
+	Start      string  `json:"start"`
+	End        string  `json:"end"`
+	TotalHours float64 `json:"total_hours"`
+}
+
+func toJSONPeriod(s report.Summary) jsonPeriod {
+	return jsonPeriod{Label: s.Label, Start: s.Start.Format("2006-01-02"), End: s.End.Format("2006-01-02"), TotalHours: s.TotalHours}
+}
+
+type jsonComparison struct {
+	GroupBy    string           `json:"group_by"`
+	Current    jsonPeriod       `json:"current"`
+	Base       jsonPeriod       `json:"base"`
+	DeltaHours float64          `json:"delta_hours"`
+	ChangePct  *float64         `json:"change_percent"` // null if the base period is empty
+	DataAsOf   string           `json:"data_as_of,omitempty"`
+	Rows       []jsonCompareRow `json:"rows"`
+}
+
+type jsonCompareRow struct {
+	Type        string   `json:"type"`
+	Name        string   `json:"name"`
+	Hours       float64  `json:"hours"`
+	Percent     float64  `json:"percent"`
+	BaseHours   float64  `json:"base_hours"`
+	BasePercent float64  `json:"base_percent"`
+	DeltaHours  float64  `json:"delta_hours"`
+	ChangePct   *float64 `json:"change_percent"` // null for groups new in the current period
+}
+
+func changePtr(pct float64, ok bool) *float64 {
+	if !ok {
+		return nil
+	}
+	return &pct
+}
+
+func compareJSON(w io.Writer, c report.Comparison) error {
+	out := jsonComparison{
+		GroupBy:    groupBy(c.Current),
+		Current:    toJSONPeriod(c.Current),
+		Base:       toJSONPeriod(c.Base),
+		DeltaHours: c.Delta(),
+		ChangePct:  changePtr(c.Change()),
+		Rows:       make([]jsonCompareRow, 0, len(c.Rows)),
+	}
+	for _, r := range c.Rows {
+		out.Rows = append(out.Rows, jsonCompareRow{
+			Type:        r.Type,
+			Name:        r.Name,
+			Hours:       r.Hours,
+			Percent:     r.Percent,
+			BaseHours:   r.BaseHours,
+			BasePercent: r.BasePercent,
+			DeltaHours:  r.Delta(),
+			ChangePct:   changePtr(r.Change()),
+		})
+	}
+	if !c.Current.AsOf.IsZero() {
+		out.DataAsOf = c.Current.AsOf.UTC().Format(time.RFC3339)
+	}
+
+	return writeJSON(w, out)
+}
+
+// compareDelimited writes one record per group plus a final "total" record,
+// each starting with both ranges. change_percent is empty for new groups
+func compareDelimited(w io.Writer, c report.Comparison, comma rune) error {
+	ranges := []string{
+		c.Current.Label, c.Current.Start.Format("2006-01-02"), c.Current.End.Format("2006-01-02"),
+		c.Base.Label, c.Base.Start.Format("2006-01-02"), c.Base.End.Format("2006-01-02"),
+	}
+	record := func(typ string, r report.CompareRow) []string {
+		pct, ok := r.Change()
+		changePct := ""
+		if ok {
+			changePct = number(pct)
+		}
+		return append(append([]string{}, ranges...), typ, r.Name,
+			number(r.Hours), number(r.Percent), number(r.BaseHours), number(r.BasePercent),
+			number(r.Delta()), changePct)
+	}
+
+	records := [][]string{{
+		"range", "start", "end", "base_range", "base_start", "base_end", "type", "name",
+		"hours", "percent", "base_hours", "base_percent", "delta_hours", "change_percent",
+	}}
+	for _, r := range c.Rows {
+		records = append(records, record(r.Type, r))
+	}
+	total := totalRow(c)
+	total.Name = ""
+	records = append(records, record("total", total))
+
+	return writeDelimited(w, comma, records)
+}
+
+// compareHTML writes a page with both periods side by side
+func compareHTML(w io.Writer, c report.Comparison) error {
+	heading := c.Current.Label + " vs " + c.Base.Label
+	p := page{
+		Title:   heading,
+		Heading: heading,
+		Caption: []string{dates(c.Current) + " vs " + dates(c.Base) + offline(c.Current.AsOf)},
+		Style:   "  table { min-width: 40rem; }\n",
+		Head: [][]pageCell{
+			{{}, {Text: c.Base.Label, Class: "num", Span: 2}, {Text: c.Current.Label, Class: "num", Span: 2}, {Text: "Change", Class: "num", Span: 2}},
+			headCells(groupHeader(c.Current), "Hours", "Share", "Hours", "Share", "Hours", "Percent"),
+		},
+	}
+	// cells are a row's numbers, the change colored by its direction
+	cells := func(r report.CompareRow) []pageCell {
+		out := []pageCell{{Text: r.Name}}
+		for i, v := range compareCells(r) {
+			if i >= 4 {
+				out = append(out, num(v, sign(r.Delta())))
+			} else {
+				out = append(out, num(v))
+			}
+		}
+		return out
+	}
+	for _, r := range c.Rows {
+		p.Rows = append(p.Rows, pageRow{Cells: cells(r)})
+	}
+	p.Foot = cells(totalRow(c))
+	return writePage(w, p)
+}
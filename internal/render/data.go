@@ -0,0 +1,143 @@
+package render
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+// Machine readable formats use stable field names and raw numbers; dates are
+// YYYY-MM-DD, timestamps RFC 3339
+
+type jsonSummary struct {
+	Label        string  `json:"label"`
+	GroupBy      string  `json:"group_by"`
+	Start        string  `json:"start"`
+	End          string  `json:"end"`
+	TotalHours   float64 `json:"total_hours"`
+	TotalPercent float64 `json:"total_percent"`
+	jsonBillable
+	jsonMoney
+	DataAsOf string    `json:"data_as_of,omitempty"`
+	Rows     []jsonRow `json:"rows"`
+}
+
+// jsonBillable is the billable split, of a row or of the total
+type jsonBillable struct {
+	BillableHours    float64 `json:"billable_hours"`
+	NonBillableHours float64 `json:"non_billable_hours"`
+	BillablePercent  float64 `json:"billable_percent"`
+}
+
+// jsonMoney is a row's or the total's amounts, only set with --amounts
+type jsonMoney struct {
+	Amounts      []jsonAmount `json:"amounts,omitempty"`
+	UnratedHours *float64     `json:"unrated_hours,omitempty"` // billable hours left out of the amounts
+}
+
+type jsonAmount struct {
+	Currency string  `json:"currency"` // empty for the company default
+	Amount   float64 `json:"amount"`
+}
+
+func toJSONMoney(s report.Summary, amounts []report.Amount, unrated float64) jsonMoney {
+	if !s.ShowAmounts {
+		return jsonMoney{}
+	}
+	m := jsonMoney{Amounts: make([]jsonAmount, 0, len(amounts)), UnratedHours: &unrated}
+	for _, a := range amounts {
+		m.Amounts = append(m.Amounts, jsonAmount{Currency: a.Currency, Amount: a.Value})
+	}
+	return m
+}
+
+type jsonRow struct {
+	Type    string  `json:"type"`
+	Name    string  `json:"name"`
+	Hours   float64 `json:"hours"`
+	Percent float64 `json:"percent"` // of the parent row, or of the total at the top level
+	jsonBillable
+	jsonMoney
+	Children []jsonRow `json:"children,omitempty"`
+}
+
+func toJSONRows(s report.Summary, rows []report.Row) []jsonRow {
+	out := make([]jsonRow, 0, len(rows))
+	for _, r := range rows {
+		jr := jsonRow{
+			Type:         r.Type,
+			Name:         r.Name,
+			Hours:        r.Hours,
+			Percent:      r.Percent,
+			jsonBillable: jsonBillable{r.BillableHours, r.NonBillableHours(), r.BillablePercent()},
+			jsonMoney:    toJSONMoney(s, r.Amounts, r.UnratedHours),
+		}
+		if len(r.Children) > 0 {
+			jr.Children = toJSONRows(s, r.Children)
+		}
+		out = append(out, jr)
+	}
+	return out
+}
+
+func renderJSON(w io.Writer, s report.Summary) error {
+	out := jsonSummary{
+		Label:        s.Label,
+		GroupBy:      groupBy(s),
+		Start:        s.Start.Format("2006-01-02"),
+		End:          s.End.Format("2006-01-02"),
+		TotalHours:   s.TotalHours,
+		TotalPercent: percentSum(s.Rows),
+		jsonBillable: jsonBillable{s.BillableHours, s.NonBillableHours(), s.BillablePercent()},
+		jsonMoney:    toJSONMoney(s, s.Amounts, s.UnratedHours),
+		Rows:         toJSONRows(s, s.Rows),
+	}
+	if !s.AsOf.IsZero() {
+		out.DataAsOf = s.AsOf.UTC().Format(time.RFC3339)
+	}
+
+	return writeJSON(w, out)
+}
+
+// renderDelimited writes one record per row (nested rows name their parent)
+// plus a final "total" record. Every record repeats the range, so files can
+// be concatenated
+func renderDelimited(w io.Writer, s report.Summary, comma rune) error {
+	start, end := s.Start.Format("2006-01-02"), s.End.Format("2006-01-02")
+	header := []string{"range", "start", "end", "type", "name", "parent", "hours", "percent", "billable_hours", "non_billable_hours", "billable_percent"}
+	// With --amounts: a column per currency, then the hours left out
+	cs := currencies(s)
+	for _, c := range cs {
+		header = append(header, strings.ToLower(strings.ReplaceAll(amountHeader(c), " ", "_")))
+	}
+	if s.ShowAmounts {
+		header = append(header, "unrated_hours")
+	}
+	withMoney := func(rec []string, r report.Row) []string {
+		for _, c := range cs {
+			rec = append(rec, number(r.Amount(c)))
+		}
+		if s.ShowAmounts {
+			rec = append(rec, number(r.UnratedHours))
+		}
+		return rec
+	}
+
+	records := [][]string{header}
+	for _, r := range flatten(s.Rows) {
+		records = append(records, withMoney([]string{s.Label, start, end, r.Type, r.Name, r.Parent, number(r.Hours), number(r.Percent),
+			number(r.BillableHours), number(r.NonBillableHours()), number(r.BillablePercent())}, r.Row))
+	}
+	total := report.Row{Amounts: s.Amounts, UnratedHours: s.UnratedHours}
+	records = append(records, withMoney([]string{s.Label, start, end, "total", "", "", number(s.TotalHours), number(percentSum(s.Rows)),
+		number(s.BillableHours), number(s.NonBillableHours()), number(s.BillablePercent())}, total))
+
+	return writeDelimited(w, comma, records)
+}
+
+func number(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,285 @@
+package render
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+// Document formats (markdown, html) are for pasting into notes and wikis.
+// Every report renders through the helpers below, so it only supplies its
+// headers and cells
+
+// writeJSON writes v indented, the way every JSON report looks
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeDelimited writes records as CSV, or TSV with a tab for comma
+func writeDelimited(w io.Writer, comma rune, records [][]string) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err := cw.WriteAll(records); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// offline is the caption suffix for old data, empty for fresh data
+func offline(asOf time.Time) string {
+	if asOf.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf(" (offline, data %s old)", age(time.Since(asOf)))
+}
+
+// markdownHeading starts a markdown report: its label and a caption line
+func markdownHeading(w io.Writer, label, caption string) {
+	fmt.Fprintf(w, "## %s\n\n%s\n\n", label, caption)
+}
+
+// bold makes markdown cells bold, leaving empty ones empty
+func bold(cells []string) []string {
+	out := make([]string, len(cells))
+	for i, c := range cells {
+		if c != "" {
+			c = "**" + c + "**"
+		}
+		out[i] = c
+	}
+	return out
+}
+
+// page is an HTML report: a heading, a caption and one table
+type page struct {
+	Title, Heading string
+	Caption        []string     // lines under the heading
+	Style          template.CSS // rules on top of pageStyle
+	Head           [][]pageCell // header rows
+	Rows           []pageRow
+	Foot           []pageCell // empty for no footer
+}
+
+type pageRow struct {
+	Class string // e.g. "child" or "over", for the report's Style
+	Cells []pageCell
+}
+
+type pageCell struct {
+	Text  string
+	Class string       // "num" for numbers, "bar" for a bar of width Bar
+	Style template.CSS // inline, e.g. an indent or a background
+	Span  int          // columns the cell spans, if more than one
+	Bar   template.CSS
+}
+
+// num is a right aligned number cell, with extra classes if any
+func num(text string, class ...string) pageCell {
+	classes := []string{"num"}
+	for _, c := range class {
+		if c != "" {
+			classes = append(classes, c)
+		}
+	}
+	return pageCell{Text: text, Class: strings.Join(classes, " ")}
+}
+
+// headCells are the header cells for column titles; all but the first are numbers
+func headCells(titles ...string) []pageCell {
+	cells := make([]pageCell, len(titles))
+	for i, t := range titles {
+		cells[i] = pageCell{Text: t}
+		if i > 0 && t != "" {
+			cells[i].Class = "num"
+		}
+	}
+	return cells
+}
+
+// sign is the class coloring a change: up, down, or none
+func sign(h float64) string {
+	switch {
+	case h > 0:
+		return "up"
+	case h < 0:
+		return "down"
+	default:
+		return ""
+	}
+}
+
+// pageStyle is the CSS every HTML report shares
+const pageStyle = `  body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2328; margin: 2rem; }
+  h1 { font-size: 1.4rem; margin-bottom: .25rem; }
+  p.range { color: #59636e; margin-top: 0; }
+  table { border-collapse: collapse; min-width: 32rem; }
+  th, td { padding: .4rem .75rem; border-bottom: 1px solid #d1d9e0; text-align: left; }
+  th { font-size: .8rem; text-transform: uppercase; color: #59636e; }
+  td.num, th.num { text-align: right; font-variant-numeric: tabular-nums; }
+  td.bar { width: 12rem; }
+  td.bar div { height: .8rem; background: #4a90d9; border-radius: 2px; }
+  td.up { color: #1a7f37; }
+  td.down { color: #cf222e; }
+  tfoot td { font-weight: 600; border-bottom: none; }
+`
+
+var pageTemplate = template.Must(template.New("page").Funcs(template.FuncMap{
+	"style": func() template.CSS { return pageStyle },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+{{style}}{{.Style}}</style>
+</head>
+<body>
+<h1>{{.Heading}}</h1>
+<p class="range">{{range $i, $line := .Caption}}{{if $i}}<br>{{end}}{{$line}}{{end}}</p>
+<table>
+  <thead>
+{{- range .Head}}
+    <tr>{{range .}}<th{{template "attrs" .}}>{{.Text}}</th>{{end}}</tr>
+{{- end}}
+  </thead>
+  <tbody>
+{{- range .Rows}}
+    <tr{{with .Class}} class="{{.}}"{{end}}>{{range .Cells}}<td{{template "attrs" .}}>{{template "content" .}}</td>{{end}}</tr>
+{{- end}}
+  </tbody>
+{{- with .Foot}}
+  <tfoot>
+    <tr>{{range .}}<td{{template "attrs" .}}>{{template "content" .}}</td>{{end}}</tr>
+  </tfoot>
+{{- end}}
+</table>
+</body>
+</html>
+{{define "attrs"}}{{with .Class}} class="{{.}}"{{end}}{{with .Style}} style="{{.}}"{{end}}{{if gt .Span 1}} colspan="{{.Span}}"{{end}}{{end}}
+{{- define "content"}}{{with .Bar}}<div style="width: {{.}}"></div>{{end}}{{.Text}}{{end}}`))
+
+// writePage writes p as a self-contained page (inline CSS, no external assets)
+func writePage(w io.Writer, p page) error {
+	return pageTemplate.Execute(w, p)
+}
+
+func renderMarkdown(w io.Writer, s report.Summary) error {
+	caption := s.Start.Format("2006-01-02") + " to " + s.End.Format("2006-01-02") + offline(s.AsOf)
+	if note := unratedNote(s); note != "" {
+		caption += fmt.Sprintf("\n\n_%s_", note)
+	}
+	markdownHeading(w, s.Label, caption)
+
+	billable := showBillable(s)
+	tw := table.NewWriter()
+	header := table.Row{groupHeader(s), "Hours", "Percent"}
+	if billable {
+		header = append(header, "Billable", "Non-billable", "Billable %")
+	}
+	for _, c := range currencies(s) {
+		header = append(header, amountHeader(c))
+	}
+	tw.AppendHeader(header)
+	for _, r := range flatten(s.Rows) {
+		name := r.Name
+		if r.Depth > 0 {
+			// Markdown collapses leading spaces, so indent with non-breaking ones
+			name = strings.Repeat("&nbsp;&nbsp;", r.Depth) + "↳ " + name
+		}
+		row := table.Row{name, fmt.Sprintf("%.1f", r.Hours), fmt.Sprintf("%.1f%%", r.Percent)}
+		if billable {
+			row = append(row, fmt.Sprintf("%.1f", r.BillableHours), fmt.Sprintf("%.1f", r.NonBillableHours()), fmt.Sprintf("%.1f%%", r.BillablePercent()))
+		}
+		for _, c := range currencies(s) {
+			row = append(row, money(r.Amount(c)))
+		}
+		tw.AppendRow(row)
+	}
+	footer := table.Row{"**Total**", fmt.Sprintf("**%.1f**", s.TotalHours), fmt.Sprintf("**%.1f%%**", percentSum(s.Rows))}
+	if billable {
+		footer = append(footer, fmt.Sprintf("**%.1f**", s.BillableHours), fmt.Sprintf("**%.1f**", s.NonBillableHours()), fmt.Sprintf("**%.1f%%**", s.BillablePercent()))
+	}
+	for _, c := range currencies(s) {
+		footer = append(footer, "**"+money(s.Amount(c))+"**")
+	}
+	tw.AppendFooter(footer)
+	tw.SetColumnConfigs(rightAligned(len(header)))
+
+	_, err := fmt.Fprintln(w, tw.RenderMarkdown())
+	return err
+}
+
+// renderHTML writes the summary as a page with a bar per row, scaled to the
+// largest share among its siblings
+func renderHTML(w io.Writer, s report.Summary) error {
+	billable := showBillable(s)
+	titles := []string{groupHeader(s), "Hours", "Percent"}
+	if billable {
+		titles = append(titles, "Billable", "Non-billable", "Billable %")
+	}
+	for _, c := range currencies(s) {
+		titles = append(titles, amountHeader(c))
+	}
+	p := page{
+		Title:   fmt.Sprintf("%s (%s to %s)", s.Label, s.Start.Format("2006-01-02"), s.End.Format("2006-01-02")),
+		Heading: s.Label,
+		Caption: []string{s.Start.Format("2006-01-02") + " to " + s.End.Format("2006-01-02") + offline(s.AsOf)},
+		Style: `  tr.child td { color: #59636e; font-size: .9rem; }
+  tr.child td.bar div { background: #a5c8ee; }
+`,
+		Head: [][]pageCell{headCells(append(titles, "")...)},
+	}
+	if note := unratedNote(s); note != "" {
+		p.Caption = append(p.Caption, note)
+	}
+
+	// cells are the numbers of a row or the total
+	cells := func(r report.Row, hours, percent float64) []pageCell {
+		out := []pageCell{num(fmt.Sprintf("%.1f", hours)), num(fmt.Sprintf("%.1f%%", percent))}
+		if billable {
+			out = append(out, num(fmt.Sprintf("%.1f", r.BillableHours)), num(fmt.Sprintf("%.1f", r.NonBillableHours())), num(fmt.Sprintf("%.1f%%", r.BillablePercent())))
+		}
+		for _, c := range currencies(s) {
+			out = append(out, num(money(r.Amount(c))))
+		}
+		return out
+	}
+	var walk func(rows []report.Row, depth int)
+	walk = func(rows []report.Row, depth int) {
+		siblingMax := 0.0
+		for _, r := range rows {
+			siblingMax = max(siblingMax, r.Percent)
+		}
+		for _, r := range rows {
+			row := pageRow{Cells: []pageCell{{Text: r.Name}}}
+			if depth > 0 {
+				row.Class = "child"
+				row.Cells[0].Style = template.CSS(fmt.Sprintf("padding-left: %.2frem", .75+1.25*float64(depth)))
+			}
+			row.Cells = append(row.Cells, cells(r, r.Hours, r.Percent)...)
+			bar := template.CSS("0%")
+			if siblingMax > 0 {
+				bar = template.CSS(fmt.Sprintf("%.2f%%", r.Percent/siblingMax*100))
+			}
+			row.Cells = append(row.Cells, pageCell{Class: "bar", Bar: bar})
+			p.Rows = append(p.Rows, row)
+			walk(r.Children, depth+1)
+		}
+	}
+	walk(s.Rows, 0)
+
+	total := report.Row{BillableHours: s.BillableHours, Hours: s.TotalHours, Amounts: s.Amounts}
+	p.Foot = append([]pageCell{{Text: "Total"}}, cells(total, s.TotalHours, percentSum(s.Rows))...)
+	p.Foot = append(p.Foot, pageCell{})
+	return writePage(w, p)
+}
@@ -0,0 +1,226 @@
+package render
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+// RenderMatrix writes a matrix (time series or cross tab) to w in the given format
+func RenderMatrix(w io.Writer, f Format, m report.Matrix) error {
+	switch f {
+	case Table:
+		return matrixTable(w, m)
+	case JSON:
+		return matrixJSON(w, m)
+	case CSV:
+		return matrixDelimited(w, m, ',')
+	case TSV:
+		return matrixDelimited(w, m, '\t')
+	case Markdown:
+		return matrixMarkdown(w, m)
+	case HTML:
+		return matrixHTML(w, m)
+	default:
+		return fmt.Errorf("unknown output format %q", f)
+	}
+}
+
+// header is the summary part of a matrix, for the shared captions
+func header(m report.Matrix) report.Summary {
+	return report.Summary{Label: m.Label, Start: m.Start, End: m.End, GroupBy: m.RowType, AsOf: m.AsOf}
+}
+
+// cell formats hours for the human readable formats; empty cells show as
+// a dash so the trend stands out
+func cell(h float64) string {
+	if h == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f", h)
+}
+
+// matrixRecords lays the matrix out as a grid: a header, one line per row,
+// then the totals, with cells formatted by num
+func matrixRecords(m report.Matrix, rowHeader, totalName string, num func(float64) string) [][]string {
+	head := append([]string{rowHeader}, m.Columns...)
+	records := [][]string{append(head, "Total")}
+	for _, r := range m.Rows {
+		rec := []string{r.Name}
+		for _, h := range r.Hours {
+			rec = append(rec, num(h))
+		}
+		records = append(records, append(rec, num(r.Total)))
+	}
+	totals := []string{totalName}
+	for _, h := range m.Totals {
+		totals = append(totals, num(h))
+	}
+	return append(records, append(totals, num(m.TotalHours)))
+}
+
+func toTableRow(rec []string) table.Row {
+	row := make(table.Row, len(rec))
+	for i, v := range rec {
+		row[i] = v
+	}
+	return row
+}
+
+// rightAligned aligns every column but the first (names) to the right
+func rightAligned(columns int) []table.ColumnConfig {
+	var cfg []table.ColumnConfig
+	for n := 2; n <= columns; n++ {
+		cfg = append(cfg, table.ColumnConfig{Number: n, Align: text.AlignRight, AlignFooter: text.AlignRight})
+	}
+	return cfg
+}
+
+func matrixTable(w io.Writer, m report.Matrix) error {
+	tw := reportTable(w, title(header(m)))
+
+	records := matrixRecords(m, groupHeader(header(m)), "Total", cell)
+	tw.AppendHeader(toTableRow(records[0]))
+	for _, rec := range records[1 : len(records)-1] {
+		tw.AppendRow(toTableRow(rec))
+	}
+	tw.AppendSeparator()
+	footer := records[len(records)-1]
+	footer[len(footer)-1] = fmt.Sprintf("%.1f hrs", m.TotalHours)
+	tw.AppendFooter(toTableRow(footer))
+	tw.SetColumnConfigs(rightAligned(len(footer)))
+
+	tw.Render()
+	return nil
+}
+
+func matrixMarkdown(w io.Writer, m report.Matrix) error {
+	markdownHeading(w, m.Label, fmt.Sprintf("%s to %s, by %s", m.Start.Format("2006-01-02"), m.End.Format("2006-01-02"), m.ColumnType)+offline(m.AsOf))
+
+	tw := table.NewWriter()
+	records := matrixRecords(m, groupHeader(header(m)), "Total", cell)
+	tw.AppendHeader(toTableRow(records[0]))
+	for _, rec := range records[1 : len(records)-1] {
+		tw.AppendRow(toTableRow(rec))
+	}
+	footer := bold(records[len(records)-1])
+	tw.AppendFooter(toTableRow(footer))
+	tw.SetColumnConfigs(rightAligned(len(footer)))
+
+	_, err := fmt.Fprintln(w, tw.RenderMarkdown())
+	return err
+}
+
+type jsonMatrix struct {
+	Label      string          `json:"label"`
+	GroupBy    string          `json:"group_by"`
+	ColumnsBy  string          `json:"columns_by"`
+	Start      string          `json:"start"`
+	End        string          `json:"end"`
+	Columns    []string        `json:"columns"`
+	TotalHours float64         `json:"total_hours"`
+	Totals     []float64       `json:"totals"` // hours per column
+	DataAsOf   string          `json:"data_as_of,omitempty"`
+	Rows       []jsonMatrixRow `json:"rows"`
+}
+
+type jsonMatrixRow struct {
+	Type  string    `json:"type"`
+	Name  string    `json:"name"`
+	Hours []float64 `json:"hours"` // aligned with columns
+	Total float64   `json:"total_hours"`
+}
+
+func matrixJSON(w io.Writer, m report.Matrix) error {
+	out := jsonMatrix{
+		Label:      m.Label,
+		GroupBy:    groupBy(header(m)),
+		ColumnsBy:  string(m.ColumnType),
+		Start:      m.Start.Format("2006-01-02"),
+		End:        m.End.Format("2006-01-02"),
+		Columns:    m.Columns,
+		TotalHours: m.TotalHours,
+		Totals:     m.Totals,
+		Rows:       make([]jsonMatrixRow, 0, len(m.Rows)),
+	}
+	for _, r := range m.Rows {
+		out.Rows = append(out.Rows, jsonMatrixRow{Type: m.RowType, Name: r.Name, Hours: r.Hours, Total: r.Total})
+	}
+	if !m.AsOf.IsZero() {
+		out.DataAsOf = m.AsOf.UTC().Format(time.RFC3339)
+	}
+
+	return writeJSON(w, out)
+}
+
+// matrixDelimited writes the matrix wide: one column per matrix column, a total
+// column, and a final "total" record. Like the summary export, every record
+// starts with the range
+func matrixDelimited(w io.Writer, m report.Matrix, comma rune) error {
+	start, end := m.Start.Format("2006-01-02"), m.End.Format("2006-01-02")
+	grid := matrixRecords(m, "name", "", number)
+	records := make([][]string, 0, len(grid))
+	for i, rec := range grid {
+		switch {
+		case i == 0:
+			rec[len(rec)-1] = "total"
+			records = append(records, append([]string{"range", "start", "end", "type"}, rec...))
+		case i == len(grid)-1:
+			records = append(records, append([]string{m.Label, start, end, "total"}, rec...))
+		default:
+			records = append(records, append([]string{m.Label, start, end, groupBy(header(m))}, rec...))
+		}
+	}
+
+	return writeDelimited(w, comma, records)
+}
+
+// matrixHTML writes the matrix as a page with a heat map, each cell darker
+// the larger it is relative to the largest one
+func matrixHTML(w io.Writer, m report.Matrix) error {
+	maxHours := 0.0
+	for _, r := range m.Rows {
+		for _, h := range r.Hours {
+			maxHours = max(maxHours, h)
+		}
+	}
+	shade := func(h float64) template.CSS {
+		if maxHours <= 0 || h <= 0 {
+			return "background: transparent"
+		}
+		return template.CSS(fmt.Sprintf("background: rgba(74, 144, 217, %.2f)", .1+.6*h/maxHours))
+	}
+
+	start, end := m.Start.Format("2006-01-02"), m.End.Format("2006-01-02")
+	p := page{
+		Title:   fmt.Sprintf("%s (%s to %s)", m.Label, start, end),
+		Heading: m.Label,
+		Caption: []string{fmt.Sprintf("%s to %s, by %s", start, end, m.ColumnType) + offline(m.AsOf)},
+		Style: `  table { min-width: 0; }
+  th, td { padding: .4rem .6rem; white-space: nowrap; }
+`,
+		Head: [][]pageCell{headCells(append(append([]string{groupHeader(header(m))}, m.Columns...), "Total")...)},
+	}
+	for _, r := range m.Rows {
+		row := pageRow{Cells: []pageCell{{Text: r.Name}}}
+		for _, h := range r.Hours {
+			c := num(cell(h))
+			c.Style = shade(h)
+			row.Cells = append(row.Cells, c)
+		}
+		row.Cells = append(row.Cells, num(cell(r.Total)))
+		p.Rows = append(p.Rows, row)
+	}
+	p.Foot = []pageCell{{Text: "Total"}}
+	for _, h := range m.Totals {
+		p.Foot = append(p.Foot, num(cell(h)))
+	}
+	p.Foot = append(p.Foot, num(cell(m.TotalHours)))
+	return writePage(w, p)
+}
@@ -0,0 +1,147 @@
+// Package render writes report summaries in the supported output formats
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+type Format string
+
+const (
+	Table    Format = "table"
+	JSON     Format = "json"
+	CSV      Format = "csv"
+	TSV      Format = "tsv"
+	Markdown Format = "markdown"
+	HTML     Format = "html"
+)
+
+// Formats lists every supported format, for flag help and validation
+var Formats = []Format{Table, JSON, CSV, TSV, Markdown, HTML}
+
+// ParseFormat validates a user supplied format name (case insensitive)
+func ParseFormat(s string) (Format, error) {
+	f := Format(strings.ToLower(strings.TrimSpace(s)))
+	if f == "md" {
+		f = Markdown
+	}
+	for _, known := range Formats {
+		if f == known {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format %q (use: %s)", s, formatList())
+}
+
+func formatList() string {
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return strings.Join(names, "|")
+}
+
+// Render writes the summary to w in the given format
+func Render(w io.Writer, f Format, s report.Summary) error {
+	switch f {
+	case Table:
+		return renderTable(w, s)
+	case JSON:
+		return renderJSON(w, s)
+	case CSV:
+		return renderDelimited(w, s, ',')
+	case TSV:
+		return renderDelimited(w, s, '\t')
+	case Markdown:
+		return renderMarkdown(w, s)
+	case HTML:
+		return renderHTML(w, s)
+	default:
+		return fmt.Errorf("unknown output format %q", f)
+	}
+}
+
+// showBillable tells the human readable formats whether to add the billable
+// columns; they're left out when nothing in the report is billable
+func showBillable(s report.Summary) bool {
+	return s.BillableHours > 0
+}
+
+// currencies lists the amount columns to render; none unless amounts were asked for
+func currencies(s report.Summary) []string {
+	if !s.ShowAmounts {
+		return nil
+	}
+	return s.Currencies()
+}
+
+// amountHeader is the column title for amounts in a currency
+func amountHeader(currency string) string {
+	if currency == "" {
+		return "Amount"
+	}
+	return "Amount " + currency
+}
+
+func money(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
+
+// percentSum adds up the row percentages (100 unless the report is empty)
+func percentSum(rows []report.Row) float64 {
+	sum := 0.0
+	for _, r := range rows {
+		sum += r.Percent
+	}
+	return sum
+}
+
+// flatRow is a row with its place in the tree, for formats without nesting
+type flatRow struct {
+	report.Row
+	Depth  int
+	Parent string // name of the parent row, empty at the top level
+}
+
+// flatten walks the rows depth first, children right after their parent
+func flatten(rows []report.Row) []flatRow {
+	var out []flatRow
+	var walk func(rows []report.Row, depth int, parent string)
+	walk = func(rows []report.Row, depth int, parent string) {
+		for _, r := range rows {
+			out = append(out, flatRow{Row: r, Depth: depth, Parent: parent})
+			walk(r.Children, depth+1, r.Name)
+		}
+	}
+	walk(rows, 0, "")
+	return out
+}
+
+// groupBy returns the summary's dimensions, defaulting to project
+func groupBy(s report.Summary) string {
+	if s.GroupBy == "" {
+		return "project"
+	}
+	return s.GroupBy
+}
+
+// groupHeader is the column title for the grouped dimensions, e.g. "Client / Project"
+func groupHeader(s report.Summary) string {
+	dims := strings.Split(groupBy(s), ",")
+	for i, d := range dims {
+		dims[i] = strings.ToUpper(d[:1]) + d[1:]
+	}
+	return strings.Join(dims, " / ")
+}
+
+// indent prefixes nested row names so the hierarchy shows in flat tables
+func indent(r flatRow) string {
+	if r.Depth == 0 {
+		return r.Name
+	}
+	return strings.Repeat("  ", r.Depth-1) + "└ " + r.Name
+}
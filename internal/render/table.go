@@ -0,0 +1,100 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+func renderTable(w io.Writer, s report.Summary) error {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(w)
+	tw.SetStyle(table.StyleLight)
+	tw.Style().Format.Header = text.FormatTitle
+	tw.SetTitle(title(s))
+
+	billable := showBillable(s)
+	header := table.Row{strings.ToUpper(groupHeader(s)), strings.ToUpper("Hours"), strings.ToUpper("Percent")}
+	if billable {
+		header = append(header, strings.ToUpper("Billable"), strings.ToUpper("Non-billable"), strings.ToUpper("Billable %"))
+	}
+	for _, c := range currencies(s) {
+		header = append(header, strings.ToUpper(amountHeader(c)))
+	}
+	tw.AppendHeader(header)
+	for _, r := range flatten(s.Rows) {
+		row := table.Row{indent(r), fmt.Sprintf("%.1f", r.Hours), fmt.Sprintf("%.1f%%", r.Percent)}
+		if billable {
+			row = append(row, fmt.Sprintf("%.1f", r.BillableHours), fmt.Sprintf("%.1f", r.NonBillableHours()), fmt.Sprintf("%.1f%%", r.BillablePercent()))
+		}
+		for _, c := range currencies(s) {
+			row = append(row, money(r.Amount(c)))
+		}
+		tw.AppendRow(row)
+	}
+
+	tw.AppendSeparator()
+	footer := table.Row{"", fmt.Sprintf("%.1f hrs", s.TotalHours), fmt.Sprintf("%.1f%%", percentSum(s.Rows))}
+	if billable {
+		footer = append(footer, fmt.Sprintf("%.1f hrs", s.BillableHours), fmt.Sprintf("%.1f hrs", s.NonBillableHours()), fmt.Sprintf("%.1f%%", s.BillablePercent()))
+	}
+	for _, c := range currencies(s) {
+		footer = append(footer, strings.TrimSpace(money(s.Amount(c))+" "+c))
+	}
+	tw.AppendFooter(footer)
+
+	tw.Render()
+	return nil
+}
+
+// reportTable is a terminal table in the style the reports share
+func reportTable(w io.Writer, title string) table.Writer {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(w)
+	tw.SetStyle(table.StyleLight)
+	tw.Style().Format.Header = text.FormatUpper
+	tw.SetTitle(title)
+	return tw
+}
+
+// title is the range caption: label, dates and, for old data, its age
+func title(s report.Summary) string {
+	t := fmt.Sprintf("%s\n%s to %s",
+		strings.ToUpper(s.Label),
+		s.Start.Format("2006-01-02"),
+		s.End.Format("2006-01-02"),
+	)
+	if !s.AsOf.IsZero() {
+		t += fmt.Sprintf("\nOffline, data %s old", age(time.Since(s.AsOf)))
+	}
+	if note := unratedNote(s); note != "" {
+		t += "\n" + note
+	}
+	return t
+}
+
+// unratedNote warns that amounts leave out hours without a rate
+func unratedNote(s report.Summary) string {
+	if !s.ShowAmounts || s.UnratedHours == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.1f billable hrs have no rate and are left out of the amounts", s.UnratedHours)
+}
+
+// age formats how old data is in the largest sensible unit
+func age(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
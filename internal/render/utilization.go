@@ -0,0 +1,211 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+// RenderUtilization writes the weekly utilization report to w in the given format
+func RenderUtilization(w io.Writer, f Format, u report.Utilization) error {
+	switch f {
+	case Table:
+		return utilizationTable(w, u)
+	case JSON:
+		return utilizationJSON(w, u)
+	case CSV:
+		return utilizationDelimited(w, u, ',')
+	case TSV:
+		return utilizationDelimited(w, u, '\t')
+	case Markdown:
+		return utilizationMarkdown(w, u)
+	case HTML:
+		return utilizationHTML(w, u)
+	default:
+		return fmt.Errorf("unknown output format %q", f)
+	}
+}
+
+// target describes what the hours are measured against
+func target(u report.Utilization) string {
+	t := fmt.Sprintf("Target %.1f hrs/week (%.1f per workday)", u.Calendar.WeeklyHours(), u.Calendar.DailyHours)
+	if u.BillableOnly {
+		t += ", billable hours only"
+	}
+	return t
+}
+
+// utilizationCaption is the range and target line of the document formats
+func utilizationCaption(u report.Utilization) string {
+	return fmt.Sprintf("%s to %s. %s", u.Start.Format("2006-01-02"), u.End.Format("2006-01-02"), target(u)) + offline(u.AsOf)
+}
+
+// utilizationPercent formats a week's utilization; weeks without expected hours have none
+func utilizationPercent(w report.UtilizationWeek) string {
+	pct, ok := w.Percent()
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", pct)
+}
+
+// utilizationCells are the human readable cells of a week: days, expected,
+// logged, utilization, difference and balance
+func utilizationCells(w report.UtilizationWeek) []string {
+	return []string{
+		w.Start.Format("01-02") + " to " + w.End.Format("01-02"),
+		fmt.Sprintf("%.1f", w.Expected), fmt.Sprintf("%.1f", w.Logged), utilizationPercent(w),
+		delta(w.Difference()), delta(w.Balance),
+	}
+}
+
+var utilizationHeader = []string{"Week", "Days", "Expected", "Logged", "Utilization", "Difference", "Balance"}
+
+func utilizationTable(w io.Writer, u report.Utilization) error {
+	t := fmt.Sprintf("%s\n%s to %s\n%s", strings.ToUpper(u.Label), u.Start.Format("2006-01-02"), u.End.Format("2006-01-02"), target(u))
+	if !u.AsOf.IsZero() {
+		t += fmt.Sprintf("\nOffline, data %s old", age(time.Since(u.AsOf)))
+	}
+	tw := reportTable(w, t)
+
+	tw.AppendHeader(toTableRow(utilizationHeader))
+	for _, wk := range u.Weeks {
+		tw.AppendRow(toTableRow(append([]string{wk.Week}, utilizationCells(wk)...)))
+	}
+	tw.AppendSeparator()
+	footer := utilizationCells(u.Total())
+	footer[0] = ""
+	tw.AppendFooter(toTableRow(append([]string{""}, footer...)))
+	tw.SetColumnConfigs(rightAligned(len(utilizationHeader))[1:]) // days stay left aligned
+
+	tw.Render()
+	return nil
+}
+
+func utilizationMarkdown(w io.Writer, u report.Utilization) error {
+	markdownHeading(w, u.Label, utilizationCaption(u))
+
+	tw := table.NewWriter()
+	tw.AppendHeader(toTableRow(utilizationHeader))
+	for _, wk := range u.Weeks {
+		tw.AppendRow(toTableRow(append([]string{wk.Week}, utilizationCells(wk)...)))
+	}
+	tw.AppendFooter(toTableRow(bold(append([]string{"Total", ""}, utilizationCells(u.Total())[1:]...))))
+	tw.SetColumnConfigs(rightAligned(len(utilizationHeader))[1:])
+
+	_, err := fmt.Fprintln(w, tw.RenderMarkdown())
+	return err
+}
+
+type jsonUtilization struct {
+	Label          string                `json:"label"`
+	Start          string                `json:"start"`
+	End            string                `json:"end"`
+	WeeklyTarget   float64               `json:"weekly_target_hours"`
+	DailyTarget    float64               `json:"daily_target_hours"`
+	BillableOnly   bool                  `json:"billable_only"`
+	ExpectedHours  float64               `json:"expected_hours"`
+	LoggedHours    float64               `json:"logged_hours"`
+	UtilizationPct *float64              `json:"utilization_percent"` // null without expected hours
+	BalanceHours   float64               `json:"balance_hours"`
+	DataAsOf       string                `json:"data_as_of,omitempty"`
+	Weeks          []jsonUtilizationWeek `json:"weeks"`
+}
+
+type jsonUtilizationWeek struct {
+	Week           string   `json:"week"`
+	Start          string   `json:"start"`
+	End            string   `json:"end"`
+	ExpectedHours  float64  `json:"expected_hours"`
+	LoggedHours    float64  `json:"logged_hours"`
+	UtilizationPct *float64 `json:"utilization_percent"`
+	DifferenceHrs  float64  `json:"difference_hours"`
+	BalanceHours   float64  `json:"balance_hours"`
+}
+
+func utilizationJSON(w io.Writer, u report.Utilization) error {
+	total := u.Total()
+	out := jsonUtilization{
+		Label:          u.Label,
+		Start:          u.Start.Format("2006-01-02"),
+		End:            u.End.Format("2006-01-02"),
+		WeeklyTarget:   u.Calendar.WeeklyHours(),
+		DailyTarget:    u.Calendar.DailyHours,
+		BillableOnly:   u.BillableOnly,
+		ExpectedHours:  total.Expected,
+		LoggedHours:    total.Logged,
+		UtilizationPct: changePtr(total.Percent()),
+		BalanceHours:   total.Balance,
+		Weeks:          make([]jsonUtilizationWeek, 0, len(u.Weeks)),
+	}
+	for _, wk := range u.Weeks {
+		out.Weeks = append(out.Weeks, jsonUtilizationWeek{
+			Week:           wk.Week,
+			Start:          wk.Start.Format("2006-01-02"),
+			End:            wk.End.Format("2006-01-02"),
+			ExpectedHours:  wk.Expected,
+			LoggedHours:    wk.Logged,
+			UtilizationPct: changePtr(wk.Percent()),
+			DifferenceHrs:  wk.Difference(),
+			BalanceHours:   wk.Balance,
+		})
+	}
+	if !u.AsOf.IsZero() {
+		out.DataAsOf = u.AsOf.UTC().Format(time.RFC3339)
+	}
+
+	return writeJSON(w, out)
+}
+
+// utilizationDelimited writes one record per week plus a final "total"
+// record. utilization_percent is empty for weeks without expected hours
+func utilizationDelimited(w io.Writer, u report.Utilization, comma rune) error {
+	record := func(typ, week string, wk report.UtilizationWeek) []string {
+		pct := ""
+		if p, ok := wk.Percent(); ok {
+			pct = number(p)
+		}
+		return []string{u.Label, typ, week, wk.Start.Format("2006-01-02"), wk.End.Format("2006-01-02"),
+			number(wk.Expected), number(wk.Logged), pct, number(wk.Difference()), number(wk.Balance)}
+	}
+
+	records := [][]string{{
+		"range", "type", "week", "start", "end", "expected_hours", "logged_hours",
+		"utilization_percent", "difference_hours", "balance_hours",
+	}}
+	for _, wk := range u.Weeks {
+		records = append(records, record("week", wk.Week, wk))
+	}
+	records = append(records, record("total", "", u.Total()))
+
+	return writeDelimited(w, comma, records)
+}
+
+// utilizationHTML writes a page with the weekly utilization, differences
+// colored by their sign
+func utilizationHTML(w io.Writer, u report.Utilization) error {
+	p := page{
+		Title:   fmt.Sprintf("%s (%s to %s)", u.Label, u.Start.Format("2006-01-02"), u.End.Format("2006-01-02")),
+		Heading: u.Label,
+		Caption: []string{utilizationCaption(u)},
+		Style:   "  table { min-width: 40rem; }\n",
+		Head:    [][]pageCell{headCells(utilizationHeader...)},
+	}
+	p.Head[0][1].Class = "" // days aren't a number
+	// cells are a week's cells after its name
+	cells := func(wk report.UtilizationWeek) []pageCell {
+		c := utilizationCells(wk)
+		return []pageCell{{Text: c[0]}, num(c[1]), num(c[2]), num(c[3]), num(c[4], sign(wk.Difference())), num(c[5], sign(wk.Balance))}
+	}
+	for _, wk := range u.Weeks {
+		p.Rows = append(p.Rows, pageRow{Cells: append([]pageCell{{Text: wk.Week}}, cells(wk)...)})
+	}
+	p.Foot = append([]pageCell{{Text: "Total"}}, cells(u.Total())...)
+	p.Foot[1] = pageCell{}
+	return writePage(w, p)
+}
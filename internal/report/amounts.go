@@ -0,0 +1,65 @@
+package report
+
+import (
+	"sort"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+)
+
+// Amount is money in one currency. Amounts in different currencies are
+// never added up
+type Amount struct {
+	Currency string // ISO code; empty for the Paymo company's default
+	Value    float64
+}
+
+// Rate resolves an entry's hourly rate: the entry's own, else its task's,
+// else its project's, else its user's. The currency is the project's.
+// ok is false if none of them has a rate
+func (lk Lookup) Rate(e api.TimeEntry) (rate float64, currency string, ok bool) {
+	p := lk.Projects[e.ProjectID]
+	for _, r := range []*float64{e.PricePerHour, lk.Tasks[e.TaskID].PricePerHour, p.PricePerHour, lk.Users[e.UserID].PricePerHour} {
+		if r != nil {
+			return *r, p.Currency, true
+		}
+	}
+	return 0, "", false
+}
+
+// Currencies lists the currencies a summary has amounts in, sorted
+func (s Summary) Currencies() []string {
+	cs := make([]string, 0, len(s.Amounts))
+	for _, a := range s.Amounts {
+		cs = append(cs, a.Currency)
+	}
+	return cs
+}
+
+// Amount returns the summary's total in the given currency, 0 if it has none
+func (s Summary) Amount(currency string) float64 {
+	return amountIn(s.Amounts, currency)
+}
+
+// Amount returns the row's amount in the given currency, 0 if it has none
+func (r Row) Amount(currency string) float64 {
+	return amountIn(r.Amounts, currency)
+}
+
+func amountIn(amounts []Amount, currency string) float64 {
+	for _, a := range amounts {
+		if a.Currency == currency {
+			return a.Value
+		}
+	}
+	return 0
+}
+
+// toAmounts turns per currency sums into amounts sorted by currency
+func toAmounts(sums map[string]float64) []Amount {
+	amounts := make([]Amount, 0, len(sums))
+	for c, v := range sums {
+		amounts = append(amounts, Amount{Currency: c, Value: v})
+	}
+	sort.Slice(amounts, func(i, j int) bool { return amounts[i].Currency < amounts[j].Currency })
+	return amounts
+}
@@ -0,0 +1,102 @@
+package report_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+func rate(r float64) *float64 { return &r }
+
+// rates has a rate on every level: user 1, project 1, task 10 of project 1.
+// Project 2 and its task 20 have none
+var rates = report.Lookup{
+	Projects: map[int]api.Project{
+		1: {ID: 1, Billable: true, PricePerHour: rate(100), Currency: "EUR"},
+		2: {ID: 2, Billable: true, Currency: "USD"},
+	},
+	Tasks: map[int]api.Task{
+		10: {ID: 10, ProjectID: 1, PricePerHour: rate(80)},
+		11: {ID: 11, ProjectID: 1},
+		20: {ID: 20, ProjectID: 2},
+	},
+	Users: map[int]api.User{
+		1: {ID: 1, PricePerHour: rate(50)},
+		2: {ID: 2},
+	},
+}
+
+func TestRate(t *testing.T) {
+	tests := []struct {
+		name         string
+		entry        api.TimeEntry
+		want         float64
+		wantCurrency string
+		wantOK       bool
+	}{
+		{"entry over task, project and user", api.TimeEntry{ProjectID: 1, TaskID: 10, UserID: 1, PricePerHour: rate(150)}, 150, "EUR", true},
+		{"task over project and user", api.TimeEntry{ProjectID: 1, TaskID: 10, UserID: 1}, 80, "EUR", true},
+		{"project over user", api.TimeEntry{ProjectID: 1, TaskID: 11, UserID: 1}, 100, "EUR", true},
+		{"user as the last resort", api.TimeEntry{ProjectID: 2, TaskID: 20, UserID: 1}, 50, "USD", true},
+		{"entry on a project without rate", api.TimeEntry{ProjectID: 2, TaskID: 20, UserID: 2, PricePerHour: rate(150)}, 150, "USD", true},
+		{"a zero rate still counts", api.TimeEntry{ProjectID: 1, TaskID: 10, UserID: 1, PricePerHour: rate(0)}, 0, "EUR", true},
+		{"no rate anywhere", api.TimeEntry{ProjectID: 2, TaskID: 20, UserID: 2}, 0, "", false},
+		{"unknown project, task and user", api.TimeEntry{ProjectID: 9, TaskID: 99, UserID: 9}, 0, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, currency, ok := rates.Rate(tt.entry)
+			if got != tt.want || currency != tt.wantCurrency || ok != tt.wantOK {
+				t.Errorf("Rate = %v %q %v, want %v %q %v", got, currency, ok, tt.want, tt.wantCurrency, tt.wantOK)
+			}
+		})
+	}
+}
+
+// TestMixedCurrencies checks amounts are summed per currency, never across
+func TestMixedCurrencies(t *testing.T) {
+	lk := rates
+	lk.Projects = map[int]api.Project{
+		1: {ID: 1, Name: "Euro", Billable: true, PricePerHour: rate(100), Currency: "EUR"},
+		2: {ID: 2, Name: "Dollar", Billable: true, Currency: "USD"},
+		3: {ID: 3, Name: "Default", Billable: true, PricePerHour: rate(60)},
+	}
+	entries := []api.TimeEntry{
+		{ID: 1, ProjectID: 1, TaskID: 10, UserID: 1, Duration: 3600},              // 80 EUR
+		{ID: 2, ProjectID: 1, TaskID: 11, UserID: 1, Duration: 7200},              // 200 EUR
+		{ID: 3, ProjectID: 2, TaskID: 20, UserID: 1, Duration: 3600},              // 50 USD
+		{ID: 4, ProjectID: 2, TaskID: 20, UserID: 2, Duration: 3600},              // unrated
+		{ID: 5, ProjectID: 3, UserID: 2, Duration: 3 * 3600},                      // 180 in the default currency
+		{ID: 6, ProjectID: 2, UserID: 2, Duration: 1800, PricePerHour: rate(100)}, // 50 USD
+	}
+	rows, total := report.Group(entries, lk, []report.Dimension{report.Project})
+
+	want := []report.Amount{{Currency: "", Value: 180}, {Currency: "EUR", Value: 280}, {Currency: "USD", Value: 100}}
+	if !slices.Equal(total.Amounts, want) {
+		t.Errorf("total amounts %v, want %v", total.Amounts, want)
+	}
+	if !near(total.UnratedHours, 1) {
+		t.Errorf("%.2f unrated hours, want 1", total.UnratedHours)
+	}
+	s := report.Summary{Amounts: total.Amounts}
+	if got := s.Currencies(); !slices.Equal(got, []string{"", "EUR", "USD"}) {
+		t.Errorf("currencies %q, want the default, EUR and USD", got)
+	}
+
+	// Every project is priced in its own currency only
+	wantRows := map[string][]report.Amount{
+		"Euro":    {{Currency: "EUR", Value: 280}},
+		"Dollar":  {{Currency: "USD", Value: 100}},
+		"Default": {{Currency: "", Value: 180}},
+	}
+	if len(rows) != len(wantRows) {
+		t.Errorf("got %d rows, want %d", len(rows), len(wantRows))
+	}
+	for _, r := range rows {
+		if !slices.Equal(r.Amounts, wantRows[r.Name]) {
+			t.Errorf("project %q has amounts %v, want %v", r.Name, r.Amounts, wantRows[r.Name])
+		}
+	}
+}
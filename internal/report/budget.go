@@ -0,0 +1,107 @@
+package report
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+)
+
+// Budget is the burn-down of every project with budget hours, as of Now
+type Budget struct {
+	Label string
+	Now   time.Time
+	// Window is how far back the burn rate looks
+	Window time.Duration
+	Rows   []BudgetRow
+	AsOf   time.Time // when the data was fetched, if it may be old (offline); zero otherwise
+}
+
+// BudgetRow is one project's budget against all the hours logged on it
+type BudgetRow struct {
+	Name        string
+	BudgetHours float64
+	UsedHours   float64
+	// RecentHours were logged within the budget's Window
+	RecentHours float64
+	// Exhaustion is when the budget ran out for overruns, otherwise when it
+	// will at the recent burn rate. Zero if nothing was burned recently
+	Exhaustion time.Time
+}
+
+// Remaining is what's left of the budget, negative for overruns
+func (r BudgetRow) Remaining() float64 {
+	return r.BudgetHours - r.UsedHours
+}
+
+// Burned is the share of the budget used, in percent; over 100 for overruns
+func (r BudgetRow) Burned() float64 {
+	return share(r.UsedHours, r.BudgetHours)
+}
+
+// Over tells whether more hours were logged than budgeted
+func (r BudgetRow) Over() bool {
+	return r.UsedHours > r.BudgetHours
+}
+
+// Total sums the budgets, used and recent hours of all rows
+func (b Budget) Total() BudgetRow {
+	var t BudgetRow
+	for _, r := range b.Rows {
+		t.BudgetHours += r.BudgetHours
+		t.UsedHours += r.UsedHours
+		t.RecentHours += r.RecentHours
+	}
+	return t
+}
+
+// BuildBudget adds up the entries of every project with budget hours. The
+// burn rate is the hours per day logged within window before now. Rows are
+// sorted by the share of the budget burned, largest first
+func BuildBudget(entries []api.TimeEntry, projects map[int]api.Project, now time.Time, window time.Duration) Budget {
+	byProject := make(map[int][]api.TimeEntry)
+	for _, e := range entries {
+		if p, ok := projects[e.ProjectID]; ok && budgeted(p) {
+			byProject[e.ProjectID] = append(byProject[e.ProjectID], e)
+		}
+	}
+
+	b := Budget{Now: now, Window: window}
+	since := now.Add(-window)
+	for _, p := range projects {
+		if !budgeted(p) {
+			continue
+		}
+		r := BudgetRow{Name: p.Name, BudgetHours: *p.BudgetHours}
+		project := byProject[p.ID]
+		sort.SliceStable(project, func(i, j int) bool { return project[i].Time().Before(project[j].Time()) })
+		for _, e := range project {
+			h := e.Duration / 3600
+			r.UsedHours += h
+			if r.Exhaustion.IsZero() && r.UsedHours > r.BudgetHours {
+				r.Exhaustion = e.Time()
+			}
+			if !e.Time().Before(since) {
+				r.RecentHours += h
+			}
+		}
+		if !r.Over() && r.RecentHours > 0 {
+			perDay := r.RecentHours / window.Hours() * 24
+			r.Exhaustion = now.AddDate(0, 0, int(math.Ceil(r.Remaining()/perDay)))
+		}
+		b.Rows = append(b.Rows, r)
+	}
+
+	sort.Slice(b.Rows, func(i, j int) bool {
+		if b.Rows[i].Burned() != b.Rows[j].Burned() {
+			return b.Rows[i].Burned() > b.Rows[j].Burned()
+		}
+		return b.Rows[i].Name < b.Rows[j].Name
+	})
+	return b
+}
+
+func budgeted(p api.Project) bool {
+	return p.BudgetHours != nil && *p.BudgetHours > 0
+}
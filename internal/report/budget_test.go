@@ -0,0 +1,114 @@
+package report_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+// timerEntry is a timer entry of a project, starting at t
+func timerEntry(id, project int, t time.Time, hours float64) api.TimeEntry {
+	ts := api.UnixTS(t.Unix())
+	return api.TimeEntry{ID: id, ProjectID: project, StartTime: &ts, Duration: hours * 3600}
+}
+
+func TestBuildBudget(t *testing.T) {
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	ago := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+	budget := func(h float64) *float64 { return &h }
+	projects := map[int]api.Project{
+		1: {ID: 1, Name: "On track", BudgetHours: budget(20)},
+		2: {ID: 2, Name: "Overrun", BudgetHours: budget(5)},
+		3: {ID: 3, Name: "Idle", BudgetHours: budget(10)},
+		4: {ID: 4, Name: "Untouched", BudgetHours: budget(50)},
+		5: {ID: 5, Name: "No budget"},
+		6: {ID: 6, Name: "Zero budget", BudgetHours: budget(0)},
+	}
+	// Out of order, so the overrun is only found once sorted
+	entries := []api.TimeEntry{
+		timerEntry(1, 1, ago(10), 6),
+		timerEntry(2, 1, ago(6), 4),
+		timerEntry(3, 1, ago(2), 3),
+		timerEntry(4, 2, ago(10), 1),
+		timerEntry(5, 2, ago(30), 3),
+		timerEntry(6, 2, ago(20), 2),
+		timerEntry(7, 3, ago(30), 1),
+		timerEntry(8, 5, ago(1), 8),
+		timerEntry(9, 6, ago(1), 8),
+	}
+
+	tests := []struct {
+		name       string
+		window     time.Duration
+		recent     map[string]float64 // hours within the window, per project
+		exhaustion map[string]time.Time
+	}{
+		{
+			name:       "a week",
+			window:     7 * 24 * time.Hour,
+			recent:     map[string]float64{"On track": 7, "Overrun": 0},
+			exhaustion: map[string]time.Time{"On track": now.AddDate(0, 0, 7), "Overrun": ago(10)},
+		},
+		{
+			// 13 hours in 14 days leave 7 hours for 7.5 days, rounded up
+			name:       "two weeks",
+			window:     14 * 24 * time.Hour,
+			recent:     map[string]float64{"On track": 13, "Overrun": 1},
+			exhaustion: map[string]time.Time{"On track": now.AddDate(0, 0, 8), "Overrun": ago(10)},
+		},
+		{
+			// The window includes an entry right on its start
+			name:       "two days",
+			window:     2 * 24 * time.Hour,
+			recent:     map[string]float64{"On track": 3, "Overrun": 0},
+			exhaustion: map[string]time.Time{"On track": now.AddDate(0, 0, 5), "Overrun": ago(10)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := report.BuildBudget(entries, projects, now, tt.window)
+
+			wantOrder := []string{"Overrun", "On track", "Idle", "Untouched"}
+			if len(b.Rows) != len(wantOrder) {
+				t.Fatalf("got %d rows, want the %d budgeted projects: %+v", len(b.Rows), len(wantOrder), b.Rows)
+			}
+			rows := make(map[string]report.BudgetRow)
+			for i, r := range b.Rows {
+				if r.Name != wantOrder[i] {
+					t.Errorf("row %d is %s, want %s (by share burned)", i, r.Name, wantOrder[i])
+				}
+				rows[r.Name] = r
+			}
+
+			used := map[string]float64{"On track": 13, "Overrun": 6, "Idle": 1, "Untouched": 0}
+			for name, h := range used {
+				if !near(rows[name].UsedHours, h) {
+					t.Errorf("%s used %.2f hours, want %.2f", name, rows[name].UsedHours, h)
+				}
+			}
+			for name, h := range tt.recent {
+				if !near(rows[name].RecentHours, h) {
+					t.Errorf("%s burned %.2f hours recently, want %.2f", name, rows[name].RecentHours, h)
+				}
+			}
+			for name, want := range tt.exhaustion {
+				if got := rows[name].Exhaustion; !got.Equal(want) {
+					t.Errorf("%s runs out %s, want %s", name, got, want)
+				}
+			}
+			for _, name := range []string{"Idle", "Untouched"} {
+				if !rows[name].Exhaustion.IsZero() {
+					t.Errorf("%s runs out %s, want never without recent hours", name, rows[name].Exhaustion)
+				}
+			}
+			if !rows["Overrun"].Over() || rows["On track"].Over() {
+				t.Errorf("overrun flags: Overrun %v, On track %v", rows["Overrun"].Over(), rows["On track"].Over())
+			}
+			if total := b.Total(); !near(total.BudgetHours, 85) || !near(total.UsedHours, 20) {
+				t.Errorf("total %.2f of %.2f hours, want 20 of 85", total.UsedHours, total.BudgetHours)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package report
+
+import (
+	"sort"
+)
+
+// Comparison puts two reports of the same grouping side by side, e.g. this
+// month against last month
+type Comparison struct {
+	Current, Base Summary
+	Rows          []CompareRow
+}
+
+// CompareRow is one group in either period. Groups found in only one period
+// have zero hours in the other
+type CompareRow struct {
+	Type                   string
+	Name                   string
+	Hours, Percent         float64 // current period
+	BaseHours, BasePercent float64 // base period
+}
+
+// Delta is the change in hours from the base period
+func (r CompareRow) Delta() float64 {
+	return r.Hours - r.BaseHours
+}
+
+// Change is the relative change in hours from the base period, in percent.
+// ok is false for groups that are new in the current period
+func (r CompareRow) Change() (pct float64, ok bool) {
+	if r.BaseHours == 0 {
+		return 0, false
+	}
+	return r.Delta() / r.BaseHours * 100, true
+}
+
+// Compare matches the top-level rows of two summaries by key, so groups
+// sharing a name, e.g. two clients' "Website" projects, stay apart. Rows are
+// sorted by current hours, then base hours, largest first
+func Compare(current, base Summary) Comparison {
+	index := make(map[string]int)
+	var rows []CompareRow
+	row := func(r Row) *CompareRow {
+		i, ok := index[r.Key]
+		if !ok {
+			i = len(rows)
+			index[r.Key] = i
+			rows = append(rows, CompareRow{Type: r.Type, Name: r.Name})
+		}
+		return &rows[i]
+	}
+	for _, r := range current.Rows {
+		cr := row(r)
+		cr.Hours, cr.Percent = r.Hours, r.Percent
+	}
+	for _, r := range base.Rows {
+		cr := row(r)
+		cr.BaseHours, cr.BasePercent = r.Hours, r.Percent
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Hours != rows[j].Hours {
+			return rows[i].Hours > rows[j].Hours
+		}
+		if rows[i].BaseHours != rows[j].BaseHours {
+			return rows[i].BaseHours > rows[j].BaseHours
+		}
+		return rows[i].Name < rows[j].Name
+	})
+	return Comparison{Current: current, Base: base, Rows: rows}
+}
+
+// Delta is the change in total hours from the base period
+func (c Comparison) Delta() float64 {
+	return c.Current.TotalHours - c.Base.TotalHours
+}
+
+// Change is the relative change in total hours, see CompareRow.Change
+func (c Comparison) Change() (float64, bool) {
+	return CompareRow{Hours: c.Current.TotalHours, BaseHours: c.Base.TotalHours}.Change()
+}
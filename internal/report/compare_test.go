@@ -0,0 +1,39 @@
+package report_test
+
+import (
+	"testing"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+// TestCompareSameNames checks groups sharing a name are compared one by one
+// instead of collapsing into a single row
+func TestCompareSameNames(t *testing.T) {
+	lk := report.Lookup{
+		Projects: map[int]api.Project{
+			1: {ID: 1, Name: "Website", ClientID: 11},
+			2: {ID: 2, Name: "Website", ClientID: 12},
+		},
+		Clients: map[int]api.Customer{11: {ID: 11, Name: "Acme"}, 12: {ID: 12, Name: "Globex"}},
+	}
+	summary := func(hours map[int]float64) report.Summary {
+		var entries []api.TimeEntry
+		for project, h := range hours {
+			entries = append(entries, api.TimeEntry{ID: project, ProjectID: project, Duration: h * 3600})
+		}
+		rows, total := report.Group(entries, lk, []report.Dimension{report.Project})
+		return report.Summary{Rows: rows, TotalHours: total.Hours}
+	}
+
+	c := report.Compare(summary(map[int]float64{1: 3, 2: 1}), summary(map[int]float64{1: 2, 2: 4}))
+	if len(c.Rows) != 2 {
+		t.Fatalf("got %d rows, want one per project: %+v", len(c.Rows), c.Rows)
+	}
+	want := []struct{ hours, base float64 }{{3, 2}, {1, 4}}
+	for i, r := range c.Rows {
+		if r.Name != "Website" || r.Hours != want[i].hours || r.BaseHours != want[i].base {
+			t.Errorf("row %d is %s %.0f vs %.0f hours, want Website %.0f vs %.0f", i, r.Name, r.Hours, r.BaseHours, want[i].hours, want[i].base)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package report
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+)
+
+// Pattern matches a project, client or task by ID, exact name (case
+// insensitive), glob (e.g. "web*") or regular expression (e.g. "/^web/")
+type Pattern struct {
+	raw string
+	id  int            // set for IDs (which may also be names)
+	re  *regexp.Regexp // set for /regex/ and globs
+}
+
+// ParsePattern parses a filter value; see Pattern
+func ParsePattern(s string) (Pattern, error) {
+	s = strings.TrimSpace(s)
+	p := Pattern{raw: s}
+	switch {
+	case s == "":
+		return p, fmt.Errorf("empty filter")
+	case len(s) > 2 && strings.HasPrefix(s, "/") && strings.HasSuffix(s, "/"):
+		re, err := regexp.Compile("(?i)" + s[1:len(s)-1])
+		if err != nil {
+			return p, fmt.Errorf("invalid regular expression %s: %w", s, err)
+		}
+		p.re = re
+	case strings.ContainsAny(s, "*?["):
+		re, err := CompileGlob(s)
+		if err != nil {
+			return p, fmt.Errorf("invalid pattern %q: %w", s, err)
+		}
+		p.re = re
+	default:
+		if id, err := strconv.Atoi(s); err == nil {
+			p.id = id
+		}
+	}
+	return p, nil
+}
+
+// Match tells whether the pattern matches the given ID or name
+func (p Pattern) Match(id int, name string) bool {
+	switch {
+	case p.re != nil:
+		return p.re.MatchString(name)
+	case p.id != 0:
+		return id == p.id || strings.EqualFold(name, p.raw)
+	default:
+		return strings.EqualFold(name, p.raw)
+	}
+}
+
+func (p Pattern) String() string {
+	return p.raw
+}
+
+// CompileGlob turns a glob into a case insensitive regular expression
+// matching whole names: * matches any text, ? any single character and
+// [a-z] or [^a-z] a character class; a backslash escapes the next character.
+// Unlike path.Match, * and ? match slashes too, as in "Acme / Website"
+func CompileGlob(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString(`(?is)^`)
+	runes := []rune(glob)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '*':
+			b.WriteString(`.*`)
+		case '?':
+			b.WriteString(`.`)
+		case '\\':
+			if i++; i == len(runes) {
+				return nil, fmt.Errorf("trailing backslash")
+			}
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case '[':
+			end := i + 1
+			if end < len(runes) && (runes[end] == '^' || runes[end] == '!') {
+				end++
+			}
+			for ; end < len(runes) && runes[end] != ']'; end++ {
+				if runes[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated character class")
+			}
+			class, err := globClass(runes[i+1 : end])
+			if err != nil {
+				return nil, err
+			}
+			b.WriteString(class)
+			i = end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString(`$`)
+	return regexp.Compile(b.String())
+}
+
+// globClass translates the inside of a glob's [...] to a regexp class
+func globClass(runes []rune) (string, error) {
+	var b strings.Builder
+	b.WriteString("[")
+	if len(runes) > 0 && (runes[0] == '^' || runes[0] == '!') {
+		b.WriteString("^")
+		runes = runes[1:]
+	}
+	if len(runes) == 0 {
+		return "", fmt.Errorf("empty character class")
+	}
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '-':
+			b.WriteRune(r) // a range, e.g. a-z
+		case r == '\\' && runes[i+1] == '-':
+			i++
+			b.WriteString(`\-`)
+		case r == '\\':
+			i++
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("]")
+	return b.String(), nil
+}
+
+// Filter narrows a report down to some entries. An entry must match one
+// pattern of each non-empty list, and contain Search in its description
+type Filter struct {
+	Projects, Clients, Tasks []Pattern
+	Search                   string
+}
+
+// IsZero tells whether the filter lets every entry through
+func (f Filter) IsZero() bool {
+	return len(f.Projects) == 0 && len(f.Clients) == 0 && len(f.Tasks) == 0 && f.Search == ""
+}
+
+// String describes the filter for report labels, e.g. `project web*, "onboarding"`
+func (f Filter) String() string {
+	var parts []string
+	for _, l := range []struct {
+		kind     string
+		patterns []Pattern
+	}{{"client", f.Clients}, {"project", f.Projects}, {"task", f.Tasks}} {
+		if len(l.patterns) == 0 {
+			continue
+		}
+		raw := make([]string, len(l.patterns))
+		for i, p := range l.patterns {
+			raw[i] = p.raw
+		}
+		parts = append(parts, l.kind+" "+strings.Join(raw, "|"))
+	}
+	if f.Search != "" {
+		parts = append(parts, strconv.Quote(f.Search))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Selection is a filter resolved against the projects, clients and tasks
+// it names, ready to match entries
+type Selection struct {
+	Projects map[int]bool // nil for any project
+	Tasks    map[int]bool // nil for any task
+	Search   string       // lower case
+}
+
+// Select resolves the filter's patterns with the lookup, which needs the
+// projects, plus clients and tasks if the filter names them. Every pattern
+// must match something, so typos don't silently report nothing
+func (f Filter) Select(lk Lookup) (Selection, error) {
+	sel := Selection{Search: strings.ToLower(f.Search)}
+
+	if len(f.Clients) > 0 {
+		clients, err := matchAll(f.Clients, "client", lk.Clients, func(c api.Customer) string { return c.Name })
+		if err != nil {
+			return sel, err
+		}
+		sel.Projects = make(map[int]bool)
+		for id, p := range lk.Projects {
+			if clients[p.ClientID] {
+				sel.Projects[id] = true
+			}
+		}
+	}
+	if len(f.Projects) > 0 {
+		projects, err := matchAll(f.Projects, "project", lk.Projects, func(p api.Project) string { return p.Name })
+		if err != nil {
+			return sel, err
+		}
+		if sel.Projects != nil {
+			// Both client and project filters: only projects passing both
+			for id := range sel.Projects {
+				if !projects[id] {
+					delete(sel.Projects, id)
+				}
+			}
+		} else {
+			sel.Projects = projects
+		}
+	}
+	if len(f.Tasks) > 0 {
+		tasks, err := matchAll(f.Tasks, "task", lk.Tasks, func(t api.Task) string { return t.Name })
+		if err != nil {
+			return sel, err
+		}
+		sel.Tasks = tasks
+	}
+	return sel, nil
+}
+
+// matchAll returns the IDs of the items matching any pattern, and an error
+// for the first pattern matching none
+func matchAll[T any](patterns []Pattern, kind string, items map[int]T, name func(T) string) (map[int]bool, error) {
+	ids := make(map[int]bool)
+	for _, p := range patterns {
+		found := false
+		for id, item := range items {
+			if p.Match(id, name(item)) {
+				ids[id] = true
+				found = true
+			}
+		}
+		if !found && p.re == nil {
+			return nil, fmt.Errorf("no %s matches %q (names must match in full, use e.g. %q for part of a name)", kind, p.raw, p.raw+"*")
+		}
+		if !found {
+			return nil, fmt.Errorf("no %s matches %q", kind, p.raw)
+		}
+	}
+	return ids, nil
+}
+
+// Match tells whether an entry passes the selection
+func (s Selection) Match(e api.TimeEntry) bool {
+	if s.Projects != nil && !s.Projects[e.ProjectID] {
+		return false
+	}
+	if s.Tasks != nil && !s.Tasks[e.TaskID] {
+		return false
+	}
+	return s.Search == "" || strings.Contains(strings.ToLower(e.Description), s.Search)
+}
+
+// EntryFilter is the selection for Paymo's where clause
+func (s Selection) EntryFilter() api.EntryFilter {
+	return api.EntryFilter{ProjectIDs: sortedIDs(s.Projects), TaskIDs: sortedIDs(s.Tasks), Description: s.Search}
+}
+
+func sortedIDs(set map[int]bool) []int {
+	if set == nil {
+		return nil
+	}
+	ids := make([]int, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
@@ -0,0 +1,77 @@
+package report_test
+
+import (
+	"testing"
+
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+func TestCompileGlob(t *testing.T) {
+	tests := []struct {
+		glob    string
+		match   []string
+		noMatch []string
+		wantErr bool
+	}{
+		{glob: "acme*", match: []string{"Acme", "Acme / Website", "ACME Corp"}, noMatch: []string{"The Acme"}},
+		{glob: "*/*", match: []string{"Acme / Website", "a/b/c"}, noMatch: []string{"Acme"}},
+		{glob: "web?ite", match: []string{"Website", "web/ite"}, noMatch: []string{"Webite", "Websites"}},
+		{glob: "q[1-4] report", match: []string{"Q2 report"}, noMatch: []string{"q5 report"}},
+		{glob: "[^a-c]*", match: []string{"Globex"}, noMatch: []string{"Acme"}},
+		{glob: "[!a-c]*", match: []string{"Globex"}, noMatch: []string{"Acme"}},
+		{glob: `50\% off*`, match: []string{"50% off sale"}, noMatch: []string{"50"}},
+		{glob: `\*new*`, match: []string{"*new* launch"}, noMatch: []string{"brand new"}},
+		{glob: "a.b(c)+", match: []string{"A.B(C)+"}, noMatch: []string{"axb(c)+", "a.bcc"}},
+		{glob: `[\-x]`, match: []string{"-", "x"}, noMatch: []string{"b"}},
+		{glob: "[a-", wantErr: true},
+		{glob: "[]", wantErr: true},
+		{glob: "[z-a]", wantErr: true},
+		{glob: `web\`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.glob, func(t *testing.T) {
+			re, err := report.CompileGlob(tt.glob)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			for _, s := range tt.match {
+				if !re.MatchString(s) {
+					t.Errorf("%q doesn't match %q", tt.glob, s)
+				}
+			}
+			for _, s := range tt.noMatch {
+				if re != nil && re.MatchString(s) {
+					t.Errorf("%q matches %q", tt.glob, s)
+				}
+			}
+		})
+	}
+}
+
+func TestPatternMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		id      int
+		name    string
+		want    bool
+	}{
+		{"acme*", 1, "Acme / Website", true},
+		{"*website", 1, "Acme / Website", true},
+		{"acme", 1, "Acme / Website", false},
+		{"acme / website", 1, "Acme / Website", true},
+		{"/^acme/", 1, "Acme / Website", true},
+		{"101", 101, "Website Relaunch", true},
+		{"101", 102, "Website Relaunch", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			p, err := report.ParsePattern(tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.Match(tt.id, tt.name); got != tt.want {
+				t.Errorf("Match(%d, %q) = %v, want %v", tt.id, tt.name, got, tt.want)
+			}
+		})
+	}
+}
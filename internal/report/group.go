@@ -0,0 +1,267 @@
+package report
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+)
+
+// Dimension is something entries can be grouped by
+type Dimension string
+
+const (
+	Client   Dimension = "client"
+	Project  Dimension = "project"
+	Task     Dimension = "task"
+	User     Dimension = "user"
+	Day      Dimension = "day"
+	Week     Dimension = "week"
+	Month    Dimension = "month"
+	Billable Dimension = "billable"
+	Tag      Dimension = "tag"
+)
+
+// Dimensions lists every supported dimension, for flag help and validation
+var Dimensions = []Dimension{Client, Project, Task, User, Day, Week, Month, Billable, Tag}
+
+// ParseDimensions parses a comma separated list like "client,project"
+func ParseDimensions(s string) ([]Dimension, error) {
+	var dims []Dimension
+	for _, part := range strings.Split(s, ",") {
+		d := Dimension(strings.ToLower(strings.TrimSpace(part)))
+		if !slices.Contains(Dimensions, d) {
+			return nil, fmt.Errorf("unknown dimension %q (use: %s)", part, dimensionList())
+		}
+		if slices.Contains(dims, d) {
+			return nil, fmt.Errorf("dimension %q given twice", d)
+		}
+		dims = append(dims, d)
+	}
+	return dims, nil
+}
+
+func dimensionList() string {
+	names := make([]string, len(Dimensions))
+	for i, d := range Dimensions {
+		names[i] = string(d)
+	}
+	return strings.Join(names, "|")
+}
+
+// Lookup resolves the IDs on entries to names. Only the maps needed by the
+// requested dimensions must be set
+type Lookup struct {
+	Projects  map[int]api.Project
+	Clients   map[int]api.Customer
+	Tasks     map[int]api.Task
+	TaskLists map[int]api.TaskList
+	Users     map[int]api.User
+}
+
+// IsBillable resolves whether an entry is billable: the entry's own flag if
+// Paymo sent one, else its task's setting, else its project's
+func (lk Lookup) IsBillable(e api.TimeEntry) bool {
+	if e.Billable != nil {
+		return *e.Billable
+	}
+	if t, ok := lk.Tasks[e.TaskID]; ok && t.Billable != nil {
+		return *t.Billable
+	}
+	return lk.Projects[e.ProjectID].Billable
+}
+
+const (
+	noProject = "Unassigned Project"
+	noClient  = "No Client"
+	noTask    = "No Task"
+	noTag     = "No Tag"
+)
+
+// Group builds a tree of rows, one level per dimension in order, with hours,
+// billable hours, amounts and percentages (of the parent) at every level.
+// Time dimensions are sorted chronologically, all others by share, largest
+// first. total sums up all entries. lk.Projects and lk.Tasks must be set to
+// tell billable time, lk.Users for user rates
+func Group(entries []api.TimeEntry, lk Lookup, dims []Dimension) (rows []Row, total Row) {
+	total = lk.tally(entries)
+	return lk.group(entries, dims, total.Hours), total
+}
+
+// tally sums up entries into a row without name or percentage
+func (lk Lookup) tally(entries []api.TimeEntry) Row {
+	var secs, billable, unrated float64
+	amounts := make(map[string]float64)
+	for _, e := range entries {
+		secs += e.Duration
+		// Only billable time earns money, so only it gets priced
+		if !lk.IsBillable(e) {
+			continue
+		}
+		billable += e.Duration
+		if rate, currency, ok := lk.Rate(e); ok {
+			amounts[currency] += rate * e.Duration / 3600
+		} else {
+			unrated += e.Duration
+		}
+	}
+	return Row{Hours: secs / 3600, BillableHours: billable / 3600, Amounts: toAmounts(amounts), UnratedHours: unrated / 3600}
+}
+
+// FilterBillable keeps only the billable entries, or only the non-billable
+// ones if billable is false
+func (lk Lookup) FilterBillable(entries []api.TimeEntry, billable bool) []api.TimeEntry {
+	return slices.DeleteFunc(entries, func(e api.TimeEntry) bool { return lk.IsBillable(e) != billable })
+}
+
+func (lk Lookup) group(entries []api.TimeEntry, dims []Dimension, totalHours float64) []Row {
+	if len(dims) == 0 || len(entries) == 0 {
+		return nil
+	}
+	d := dims[0]
+	keyOf := lk.keyFunc(d, entries)
+
+	type bucket struct {
+		name    string
+		secs    float64
+		entries []api.TimeEntry
+	}
+	buckets := make(map[string]*bucket)
+	var keys []string
+	for _, e := range entries {
+		key, name := keyOf(e)
+		b, ok := buckets[key]
+		if !ok {
+			b = &bucket{name: name}
+			buckets[key] = b
+			keys = append(keys, key)
+		}
+		b.secs += e.Duration
+		b.entries = append(b.entries, e)
+	}
+
+	if chronological(d) {
+		sort.Strings(keys)
+	} else {
+		sort.Slice(keys, func(i, j int) bool {
+			bi, bj := buckets[keys[i]], buckets[keys[j]]
+			if bi.secs != bj.secs {
+				return bi.secs > bj.secs
+			}
+			return bi.name < bj.name
+		})
+	}
+
+	rows := make([]Row, 0, len(keys))
+	for _, key := range keys {
+		b := buckets[key]
+		row := lk.tally(b.entries)
+		row.Type = string(d)
+		row.Key = key
+		row.Name = b.name
+		row.Percent = share(row.Hours, totalHours)
+		row.Children = lk.group(b.entries, dims[1:], row.Hours)
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func chronological(d Dimension) bool {
+	return d == Day || d == Week || d == Month
+}
+
+// periodKey names the day, ISO week or month t falls in. Keys sort
+// chronologically as strings
+func periodKey(d Dimension, t time.Time) string {
+	switch d {
+	case Day:
+		return t.Format("2006-01-02")
+	case Week:
+		y, w := t.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", y, w)
+	default:
+		return t.Format("2006-01")
+	}
+}
+
+// keyFunc returns how to bucket entries by d: a sortable key and a display name.
+// entries is the group being split, used to name tasks unambiguously
+func (lk Lookup) keyFunc(d Dimension, entries []api.TimeEntry) func(api.TimeEntry) (string, string) {
+	switch d {
+	case Client:
+		return func(e api.TimeEntry) (string, string) {
+			cid := lk.Projects[e.ProjectID].ClientID
+			if name := lk.Clients[cid].Name; name != "" {
+				return fmt.Sprint(cid), name
+			}
+			return "0", noClient
+		}
+	case Project:
+		return func(e api.TimeEntry) (string, string) {
+			if name := lk.Projects[e.ProjectID].Name; name != "" {
+				return fmt.Sprint(e.ProjectID), name
+			}
+			return "0", noProject
+		}
+	case Task:
+		// Within one project tasks are told apart by tasklist, across
+		// projects by project
+		oneProject := true
+		for _, e := range entries {
+			oneProject = oneProject && e.ProjectID == entries[0].ProjectID
+		}
+		return func(e api.TimeEntry) (string, string) {
+			t, ok := lk.Tasks[e.TaskID]
+			if !ok {
+				return "0", noTask
+			}
+			prefix := lk.Projects[t.ProjectID].Name
+			if oneProject {
+				prefix = lk.TaskLists[t.TaskListID].Name
+			}
+			if prefix == "" {
+				return fmt.Sprint(t.ID), t.Name
+			}
+			return fmt.Sprint(t.ID), prefix + " › " + t.Name
+		}
+	case User:
+		return func(e api.TimeEntry) (string, string) {
+			u := lk.Users[e.UserID]
+			switch {
+			case u.Name != "":
+				return fmt.Sprint(e.UserID), u.Name
+			case u.Email != "":
+				return fmt.Sprint(e.UserID), u.Email
+			default:
+				return fmt.Sprint(e.UserID), fmt.Sprintf("User %d", e.UserID)
+			}
+		}
+	case Day, Week, Month:
+		return func(e api.TimeEntry) (string, string) {
+			k := periodKey(d, e.Time())
+			return k, k
+		}
+	case Billable:
+		return func(e api.TimeEntry) (string, string) {
+			if lk.IsBillable(e) {
+				return "billable", "Billable"
+			}
+			return "non-billable", "Non-billable"
+		}
+	case Tag:
+		return func(e api.TimeEntry) (string, string) {
+			if len(e.Tags) == 0 {
+				return "", noTag
+			}
+			tags := slices.Clone(e.Tags)
+			sort.Strings(tags)
+			k := strings.Join(tags, ", ")
+			return k, k
+		}
+	default:
+		panic("report: unknown dimension " + string(d))
+	}
+}
@@ -0,0 +1,153 @@
+package report_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+	"github.com/Ma-Kas/paymostats/internal/api/paymotest"
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+// demo fetches two weeks of the fake's demo data through the API client
+func demo(t *testing.T) (paymotest.Fixtures, []api.TimeEntry, report.Lookup) {
+	t.Helper()
+	now := time.Date(2026, 3, 20, 18, 0, 0, 0, time.UTC)
+	fixtures := paymotest.Demo(now)
+	srv := paymotest.NewServer(&paymotest.Fake{Fixtures: fixtures})
+	t.Cleanup(srv.Close)
+
+	ctx := context.Background()
+	c := api.NewClient("demo", api.WithBaseURL(srv.URL))
+	start, end := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 13, 23, 59, 59, 0, time.UTC)
+	entries, err := c.Entries(ctx, 1, start, end)
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	var lk report.Lookup
+	if lk.Projects, err = c.Projects(ctx); err != nil {
+		t.Fatalf("Projects: %v", err)
+	}
+	if lk.Clients, err = c.Clients(ctx); err != nil {
+		t.Fatalf("Clients: %v", err)
+	}
+	if lk.Tasks, err = c.Tasks(ctx); err != nil {
+		t.Fatalf("Tasks: %v", err)
+	}
+
+	// Keep the fixtures of the fetched window only, to compare with
+	var window []paymotest.Entry
+	for _, e := range fixtures.Entries {
+		if e.UserID == 1 && !e.Start.Before(start) && !e.Start.After(end) {
+			window = append(window, e)
+		}
+	}
+	fixtures.Entries = window
+	return fixtures, entries, lk
+}
+
+func TestGroup(t *testing.T) {
+	fixtures, entries, lk := demo(t)
+
+	var wantHours, wantBillable float64
+	billableProject := make(map[int]bool)
+	for _, p := range fixtures.Projects {
+		billableProject[p.ID] = p.Billable
+	}
+	for _, e := range fixtures.Entries {
+		h := e.Duration.Hours()
+		wantHours += h
+		// The entry's own flag wins, and standups (task x11) are never billable
+		switch {
+		case e.Billable != nil:
+			if *e.Billable {
+				wantBillable += h
+			}
+		case billableProject[e.ProjectID] && e.TaskID%100 != 11:
+			wantBillable += h
+		}
+	}
+
+	tests := []struct {
+		name string
+		dims []report.Dimension
+	}{
+		{"project", []report.Dimension{report.Project}},
+		{"client", []report.Dimension{report.Client}},
+		{"task", []report.Dimension{report.Task}},
+		{"client and project", []report.Dimension{report.Client, report.Project}},
+		{"day", []report.Dimension{report.Day}},
+		{"billable", []report.Dimension{report.Billable}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, total := report.Group(entries, lk, tt.dims)
+			if !near(total.Hours, wantHours) {
+				t.Errorf("total %.2f hours, want %.2f", total.Hours, wantHours)
+			}
+			if !near(total.BillableHours, wantBillable) {
+				t.Errorf("total %.2f billable hours, want %.2f", total.BillableHours, wantBillable)
+			}
+			checkRows(t, rows, total.Hours)
+		})
+	}
+}
+
+// checkRows checks rows add up to their parent's hours and 100%, at every level
+func checkRows(t *testing.T, rows []report.Row, parentHours float64) {
+	t.Helper()
+	var hours, percent float64
+	for _, r := range rows {
+		hours += r.Hours
+		percent += r.Percent
+		if len(r.Children) > 0 {
+			checkRows(t, r.Children, r.Hours)
+		}
+	}
+	if !near(hours, parentHours) {
+		t.Errorf("rows add up to %.2f hours, want %.2f", hours, parentHours)
+	}
+	if !near(percent, 100) {
+		t.Errorf("rows add up to %.2f%%, want 100%%", percent)
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+// TestAmounts checks only billable time is priced, and only billable time
+// without a rate counts as unrated
+func TestAmounts(t *testing.T) {
+	yes, no := true, false
+	lk := report.Lookup{Projects: map[int]api.Project{
+		1: {ID: 1, Billable: true, PricePerHour: rate(100), Currency: "EUR"},
+		2: {ID: 2, Billable: true},
+		3: {ID: 3, Billable: false, PricePerHour: rate(50), Currency: "EUR"},
+	}}
+	tests := []struct {
+		name        string
+		entry       api.TimeEntry
+		wantAmount  float64
+		wantUnrated float64
+	}{
+		{"billable with rate", api.TimeEntry{ProjectID: 1, Duration: 7200}, 200, 0},
+		{"billable without rate", api.TimeEntry{ProjectID: 2, Duration: 7200}, 0, 2},
+		{"non-billable project with rate", api.TimeEntry{ProjectID: 3, Duration: 7200}, 0, 0},
+		{"non-billable entry on billable project", api.TimeEntry{ProjectID: 1, Duration: 7200, Billable: &no}, 0, 0},
+		{"billable entry on non-billable project", api.TimeEntry{ProjectID: 3, Duration: 7200, Billable: &yes}, 100, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, total := report.Group([]api.TimeEntry{tt.entry}, lk, []report.Dimension{report.Project})
+			if got := total.Amount("EUR"); !near(got, tt.wantAmount) {
+				t.Errorf("amount %.2f EUR, want %.2f", got, tt.wantAmount)
+			}
+			if !near(total.UnratedHours, tt.wantUnrated) {
+				t.Errorf("%.2f unrated hours, want %.2f", total.UnratedHours, tt.wantUnrated)
+			}
+		})
+	}
+}
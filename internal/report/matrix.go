@@ -0,0 +1,132 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+)
+
+// Matrix is a cross tab: hours per row (e.g. project) per column, either a
+// period (day, week or month) for time series or another dimension (e.g.
+// users x projects), with row and column totals
+type Matrix struct {
+	Label      string
+	Start, End time.Time
+	RowType    string    // the dimension the rows group by, e.g. "project"
+	ColumnType Dimension // the dimension the columns group by, e.g. Week
+	Columns    []string  // column names; periods in date order, others largest first
+	Rows       []MatrixRow
+	Totals     []float64 // hours per column, across all rows
+	TotalHours float64
+	AsOf       time.Time // when the data was fetched, if it may be old (offline); zero otherwise
+}
+
+type MatrixRow struct {
+	Name  string
+	Hours []float64 // one per column, aligned with Matrix.Columns
+	Total float64
+}
+
+// BuildMatrix spreads entries over rows and columns by two different
+// dimensions. For time columns every period between start and end is listed,
+// including empty ones, so gaps show. Rows are sorted by total, largest first
+func BuildMatrix(entries []api.TimeEntry, lk Lookup, rows, cols Dimension, start, end time.Time) (Matrix, error) {
+	if rows == cols || chronological(rows) && chronological(cols) {
+		return Matrix{}, fmt.Errorf("rows and columns can't both be grouped by %s", timeOr(rows, cols))
+	}
+
+	colOf := lk.keyFunc(cols, entries)
+	var keys, names []string
+	col := make(map[string]int)
+	addColumn := func(key, name string) {
+		if _, ok := col[key]; !ok {
+			col[key] = len(keys)
+			keys = append(keys, key)
+			names = append(names, name)
+		}
+	}
+	if chronological(cols) {
+		// Entries on the edges of the range may fall just outside it, so
+		// their periods are added too
+		for t := start.UTC(); !t.After(end); t = t.AddDate(0, 0, 1) {
+			k := periodKey(cols, t)
+			addColumn(k, k)
+		}
+	}
+	for _, e := range entries {
+		addColumn(colOf(e))
+	}
+
+	m := Matrix{
+		Start:      start,
+		End:        end,
+		RowType:    string(rows),
+		ColumnType: cols,
+		Totals:     make([]float64, len(keys)),
+	}
+	rowOf := lk.keyFunc(rows, entries)
+	index := make(map[string]int)
+	for _, e := range entries {
+		key, name := rowOf(e)
+		i, ok := index[key]
+		if !ok {
+			i = len(m.Rows)
+			index[key] = i
+			m.Rows = append(m.Rows, MatrixRow{Name: name, Hours: make([]float64, len(keys))})
+		}
+		h := e.Duration / 3600
+		ck, _ := colOf(e)
+		c := col[ck]
+		m.Rows[i].Hours[c] += h
+		m.Rows[i].Total += h
+		m.Totals[c] += h
+		m.TotalHours += h
+	}
+
+	// Order the columns: by date, or by hours like the rows
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	if chronological(cols) {
+		sort.Slice(order, func(i, j int) bool { return keys[order[i]] < keys[order[j]] })
+	} else {
+		sort.SliceStable(order, func(i, j int) bool {
+			if m.Totals[order[i]] != m.Totals[order[j]] {
+				return m.Totals[order[i]] > m.Totals[order[j]]
+			}
+			return names[order[i]] < names[order[j]]
+		})
+	}
+	m.Columns = permute(names, order)
+	m.Totals = permute(m.Totals, order)
+	for i := range m.Rows {
+		m.Rows[i].Hours = permute(m.Rows[i].Hours, order)
+	}
+
+	sort.SliceStable(m.Rows, func(i, j int) bool {
+		if m.Rows[i].Total != m.Rows[j].Total {
+			return m.Rows[i].Total > m.Rows[j].Total
+		}
+		return m.Rows[i].Name < m.Rows[j].Name
+	})
+	return m, nil
+}
+
+// timeOr names the clashing dimension for errors: "time" for two time dimensions
+func timeOr(rows, cols Dimension) Dimension {
+	if rows != cols {
+		return "time"
+	}
+	return rows
+}
+
+func permute[T any](s []T, order []int) []T {
+	out := make([]T, len(order))
+	for i, j := range order {
+		out[i] = s[j]
+	}
+	return out
+}
@@ -0,0 +1,141 @@
+package report_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+func TestBuildMatrix(t *testing.T) {
+	fixtures, entries, lk := demo(t)
+	start, end := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 13, 23, 59, 59, 0, time.UTC)
+
+	var wantHours float64
+	for _, e := range fixtures.Entries {
+		wantHours += e.Duration.Hours()
+	}
+	days := []string{
+		"2026-03-02", "2026-03-03", "2026-03-04", "2026-03-05", "2026-03-06", "2026-03-07", "2026-03-08",
+		"2026-03-09", "2026-03-10", "2026-03-11", "2026-03-12", "2026-03-13",
+	}
+
+	tests := []struct {
+		name       string
+		rows, cols report.Dimension
+		columns    []string // nil to check the order by hours instead
+		empty      []string // columns without any hours
+	}{
+		{name: "projects by week", rows: report.Project, cols: report.Week, columns: []string{"2026-W10", "2026-W11"}},
+		{name: "projects by day", rows: report.Project, cols: report.Day, columns: days, empty: []string{"2026-03-07", "2026-03-08"}},
+		{name: "projects by month", rows: report.Project, cols: report.Month, columns: []string{"2026-03"}},
+		{name: "weeks by project", rows: report.Week, cols: report.Project},
+		{name: "tasks by client", rows: report.Task, cols: report.Client},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := report.BuildMatrix(entries, lk, tt.rows, tt.cols, start, end)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !near(m.TotalHours, wantHours) {
+				t.Errorf("total %.2f hours, want %.2f", m.TotalHours, wantHours)
+			}
+			if tt.columns != nil && !slices.Equal(m.Columns, tt.columns) {
+				t.Errorf("columns %v, want %v", m.Columns, tt.columns)
+			}
+			if len(m.Totals) != len(m.Columns) {
+				t.Fatalf("%d column totals for %d columns", len(m.Totals), len(m.Columns))
+			}
+			for c, total := range m.Totals {
+				if tt.columns == nil && c > 0 && total > m.Totals[c-1] {
+					t.Errorf("column %s (%.2f hours) comes after %s (%.2f hours)", m.Columns[c], total, m.Columns[c-1], m.Totals[c-1])
+				}
+				if slices.Contains(tt.empty, m.Columns[c]) != (total == 0) {
+					t.Errorf("column %s has %.2f hours, want it empty: %v", m.Columns[c], total, slices.Contains(tt.empty, m.Columns[c]))
+				}
+			}
+
+			colSums := make([]float64, len(m.Columns))
+			var rowSum float64
+			for i, r := range m.Rows {
+				if len(r.Hours) != len(m.Columns) {
+					t.Fatalf("row %s has %d cells for %d columns", r.Name, len(r.Hours), len(m.Columns))
+				}
+				if i > 0 && r.Total > m.Rows[i-1].Total {
+					t.Errorf("row %s (%.2f hours) comes after %s (%.2f hours)", r.Name, r.Total, m.Rows[i-1].Name, m.Rows[i-1].Total)
+				}
+				var sum float64
+				for c, h := range r.Hours {
+					sum += h
+					colSums[c] += h
+				}
+				if !near(sum, r.Total) {
+					t.Errorf("row %s cells add up to %.2f hours, its total is %.2f", r.Name, sum, r.Total)
+				}
+				rowSum += r.Total
+			}
+			if !near(rowSum, m.TotalHours) {
+				t.Errorf("row totals add up to %.2f hours, want %.2f", rowSum, m.TotalHours)
+			}
+			for c, sum := range colSums {
+				if !near(sum, m.Totals[c]) {
+					t.Errorf("column %s cells add up to %.2f hours, its total is %.2f", m.Columns[c], sum, m.Totals[c])
+				}
+			}
+		})
+	}
+}
+
+// TestBuildMatrixProjectWeeks checks the cells against the fixtures
+func TestBuildMatrixProjectWeeks(t *testing.T) {
+	fixtures, entries, lk := demo(t)
+	start, end := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 13, 23, 59, 59, 0, time.UTC)
+	m, err := report.BuildMatrix(entries, lk, report.Project, report.Week, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make(map[int]string)
+	for _, p := range fixtures.Projects {
+		names[p.ID] = p.Name
+	}
+	want := make(map[string][]float64) // by project name, one per week
+	for _, e := range fixtures.Entries {
+		name := names[e.ProjectID]
+		if want[name] == nil {
+			want[name] = make([]float64, 2)
+		}
+		week := 0
+		if e.Start.Day() >= 9 {
+			week = 1
+		}
+		want[name][week] += e.Duration.Hours()
+	}
+	if len(m.Rows) != len(want) {
+		t.Errorf("got %d rows, want one per project (%d)", len(m.Rows), len(want))
+	}
+	for _, r := range m.Rows {
+		w := want[r.Name]
+		if w == nil || !near(r.Hours[0], w[0]) || !near(r.Hours[1], w[1]) {
+			t.Errorf("%s has %.2f and %.2f hours, want %v", r.Name, r.Hours[0], r.Hours[1], w)
+		}
+	}
+}
+
+func TestBuildMatrixErrors(t *testing.T) {
+	_, entries, lk := demo(t)
+	tests := []struct{ rows, cols report.Dimension }{
+		{report.Project, report.Project},
+		{report.Week, report.Day},
+		{report.Month, report.Month},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.rows)+" by "+string(tt.cols), func(t *testing.T) {
+			if _, err := report.BuildMatrix(entries, lk, tt.rows, tt.cols, time.Time{}, time.Time{}); err == nil {
+				t.Error("no error")
+			}
+		})
+	}
+}
@@ -1,35 +1,63 @@
 package report
 
 import (
-	"sort"
-
-	"github.com/Ma-Kas/paymostats/internal/api"
+	"time"
 )
 
+// Summary is a finished report: the breakdown of a date range by GroupBy
+// (e.g. "project" or "client,project"), ready for any renderer
+type Summary struct {
+	Label      string
+	Start, End time.Time
+	GroupBy    string
+	Rows       []Row
+	TotalHours float64
+	// Hours of the total that are billable, see Lookup.IsBillable
+	BillableHours float64
+	// Money for the billable hours, see Lookup.Rate. Only rendered with ShowAmounts
+	Amounts      []Amount
+	UnratedHours float64 // billable hours without any rate, not in Amounts
+	ShowAmounts  bool
+	AsOf         time.Time // when the data was fetched, if it may be old (offline); zero otherwise
+}
+
+// NonBillableHours is the part of the total that isn't billable
+func (s Summary) NonBillableHours() float64 {
+	return s.TotalHours - s.BillableHours
+}
+
+// BillablePercent is the billable share of the total
+func (s Summary) BillablePercent() float64 {
+	return share(s.BillableHours, s.TotalHours)
+}
+
 type Row struct {
-	Name    string
-	Hours   float64
-	Percent float64
+	Type          string // the dimension the row groups by, e.g. "project" or "client"
+	Key           string // tells groups apart where names may repeat, e.g. the project ID
+	Name          string
+	Hours         float64
+	Percent       float64 // share of the parent row's hours; of the total for top-level rows
+	BillableHours float64
+	Amounts       []Amount // billable hours x rate, one per currency
+	UnratedHours  float64  // billable hours without any rate, not in Amounts
+	// Breakdown by the next dimension, e.g. the projects of a client
+	Children []Row
 }
 
-func Build(entries []api.TimeEntry, projects map[int]string) (rows []Row, totalHours float64) {
-	projectTotals := make(map[int]float64)
-	var total float64
-	for _, e := range entries {
-		projectTotals[e.ProjectID] += e.Duration
-		total += e.Duration
-	}
-	totalHours = total / 3600
-
-	for pid, secs := range projectTotals {
-		name := projects[pid]
-		if name == "" {
-			name = "Unassigned Project"
-		}
-		h := secs / 3600
-		pct := (secs / total) * 100
-		rows = append(rows, Row{Name: name, Hours: h, Percent: pct})
+// NonBillableHours is the part of the row's hours that isn't billable
+func (r Row) NonBillableHours() float64 {
+	return r.Hours - r.BillableHours
+}
+
+// BillablePercent is the billable share of the row's hours
+func (r Row) BillablePercent() float64 {
+	return share(r.BillableHours, r.Hours)
+}
+
+// share is part as a percentage of whole, 0 for an empty whole
+func share(part, whole float64) float64 {
+	if whole <= 0 {
+		return 0
 	}
-	sort.Slice(rows, func(i, j int) bool { return rows[i].Percent > rows[j].Percent })
-	return rows, totalHours
+	return part / whole * 100
 }
@@ -0,0 +1,115 @@
+package report
+
+import (
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+)
+
+// Calendar says how many hours of work are expected on which days
+type Calendar struct {
+	DailyHours float64
+	Workdays   [7]bool // indexed by time.Weekday
+	// Holidays are days off, keyed YYYY-MM-DD, or MM-DD for every year
+	Holidays map[string]bool
+}
+
+// Expected is the hours expected on the given day
+func (c Calendar) Expected(day time.Time) float64 {
+	if !c.Workdays[day.Weekday()] || c.Holidays[day.Format("2006-01-02")] || c.Holidays[day.Format("01-02")] {
+		return 0
+	}
+	return c.DailyHours
+}
+
+// WeeklyHours is the target of a week without holidays
+func (c Calendar) WeeklyHours() float64 {
+	var n float64
+	for _, ok := range c.Workdays {
+		if ok {
+			n++
+		}
+	}
+	return n * c.DailyHours
+}
+
+// Utilization is the logged hours of a range against the target, week by week
+type Utilization struct {
+	Label      string
+	Start, End time.Time
+	Calendar   Calendar
+	// BillableOnly means only billable hours were counted towards the target
+	BillableOnly bool
+	Weeks        []UtilizationWeek
+	AsOf         time.Time // when the data was fetched, if it may be old (offline); zero otherwise
+}
+
+// UtilizationWeek is one ISO week of the range. The first and last weeks
+// may be partial, then only their days within the range count
+type UtilizationWeek struct {
+	Week             string    // e.g. "2025-W07"
+	Start, End       time.Time // first and last day of the week within the range
+	Expected, Logged float64
+	// Balance is the overtime (positive) or undertime (negative) summed up
+	// from the start of the range to the end of this week
+	Balance float64
+}
+
+// Difference is the week's overtime (positive) or undertime (negative)
+func (w UtilizationWeek) Difference() float64 {
+	return w.Logged - w.Expected
+}
+
+// Percent is the logged hours as a share of the expected ones. ok is false
+// for weeks without expected hours (e.g. all holidays)
+func (w UtilizationWeek) Percent() (pct float64, ok bool) {
+	if w.Expected <= 0 {
+		return 0, false
+	}
+	return w.Logged / w.Expected * 100, true
+}
+
+// Total sums up the weeks; its Balance is the final balance
+func (u Utilization) Total() UtilizationWeek {
+	t := UtilizationWeek{Start: u.Start, End: u.End}
+	for _, w := range u.Weeks {
+		t.Expected += w.Expected
+		t.Logged += w.Logged
+	}
+	t.Balance = t.Difference()
+	return t
+}
+
+// BuildUtilization compares the entries with the calendar for every week
+// from start to end, both days included
+func BuildUtilization(entries []api.TimeEntry, cal Calendar, start, end time.Time) Utilization {
+	u := Utilization{Start: start, End: end, Calendar: cal}
+
+	logged := make(map[string]float64)
+	for _, e := range entries {
+		logged[e.Time().Format("2006-01-02")] += e.Duration / 3600
+	}
+
+	index := make(map[string]int)
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	for day := first; !day.After(end); day = day.AddDate(0, 0, 1) {
+		key := periodKey(Week, day)
+		i, ok := index[key]
+		if !ok {
+			i = len(u.Weeks)
+			index[key] = i
+			u.Weeks = append(u.Weeks, UtilizationWeek{Week: key, Start: day})
+		}
+		w := &u.Weeks[i]
+		w.End = day
+		w.Expected += cal.Expected(day)
+		w.Logged += logged[day.Format("2006-01-02")]
+	}
+
+	var balance float64
+	for i := range u.Weeks {
+		balance += u.Weeks[i].Difference()
+		u.Weeks[i].Balance = balance
+	}
+	return u
+}
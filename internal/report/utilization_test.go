@@ -0,0 +1,115 @@
+package report_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+// weekdays is a calendar of 8 hours Monday to Friday, with one holiday in
+// 2026 only and one every year
+var weekdays = report.Calendar{
+	DailyHours: 8,
+	Workdays:   [7]bool{time.Monday: true, time.Tuesday: true, time.Wednesday: true, time.Thursday: true, time.Friday: true},
+	Holidays:   map[string]bool{"2026-03-04": true, "03-06": true},
+}
+
+func TestCalendarExpected(t *testing.T) {
+	tests := []struct {
+		day  string
+		want float64
+	}{
+		{"2026-03-03", 8}, // Tuesday
+		{"2026-03-07", 0}, // Saturday
+		{"2026-03-04", 0}, // holiday in 2026
+		{"2027-03-04", 8}, // the same date a year later is a Thursday at work
+		{"2026-03-06", 0}, // holiday every year, on a Friday
+		{"2025-03-06", 0}, // and on a Thursday
+	}
+	for _, tt := range tests {
+		t.Run(tt.day, func(t *testing.T) {
+			d, err := time.Parse("2006-01-02", tt.day)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := weekdays.Expected(d); got != tt.want {
+				t.Errorf("Expected = %.1f, want %.1f", got, tt.want)
+			}
+		})
+	}
+	if got := weekdays.WeeklyHours(); got != 40 {
+		t.Errorf("WeeklyHours = %.1f, want 40", got)
+	}
+}
+
+func TestBuildUtilization(t *testing.T) {
+	at := func(day string, hours float64) api.TimeEntry {
+		d, err := time.Parse("2006-01-02", day)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return timerEntry(0, 1, d.Add(9*time.Hour), hours)
+	}
+	entries := []api.TimeEntry{
+		at("2026-02-25", 8), // before the range
+		at("2026-02-26", 6),
+		at("2026-02-28", 2), // a Saturday: logged, but nothing expected
+		at("2026-03-02", 9),
+		at("2026-03-04", 3), // a holiday
+		at("2026-03-05", 8),
+		at("2026-03-09", 8),
+		at("2026-03-10", 10),
+		at("2026-03-11", 8), // after the range
+	}
+	// Thursday to the Tuesday twelve days later, so both edge weeks are partial
+	start := time.Date(2026, 2, 26, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 3, 10, 23, 59, 59, 0, time.UTC)
+	u := report.BuildUtilization(entries, weekdays, start, end)
+
+	want := []struct {
+		week, start, end          string
+		expected, logged, balance float64
+	}{
+		{"2026-W09", "2026-02-26", "2026-03-01", 16, 8, -8},
+		{"2026-W10", "2026-03-02", "2026-03-08", 24, 20, -12}, // two holidays
+		{"2026-W11", "2026-03-09", "2026-03-10", 16, 18, -10},
+	}
+	if len(u.Weeks) != len(want) {
+		t.Fatalf("got %d weeks, want %d: %+v", len(u.Weeks), len(want), u.Weeks)
+	}
+	for i, w := range want {
+		got := u.Weeks[i]
+		if got.Week != w.week || got.Start.Format("2006-01-02") != w.start || got.End.Format("2006-01-02") != w.end {
+			t.Errorf("week %d is %s from %s to %s, want %s from %s to %s", i,
+				got.Week, got.Start.Format("2006-01-02"), got.End.Format("2006-01-02"), w.week, w.start, w.end)
+		}
+		if !near(got.Expected, w.expected) || !near(got.Logged, w.logged) || !near(got.Balance, w.balance) {
+			t.Errorf("%s: expected %.1f, logged %.1f, balance %+.1f; want %.1f, %.1f, %+.1f",
+				w.week, got.Expected, got.Logged, got.Balance, w.expected, w.logged, w.balance)
+		}
+	}
+
+	total := u.Total()
+	if !near(total.Expected, 56) || !near(total.Logged, 46) || !near(total.Balance, -10) {
+		t.Errorf("total: expected %.1f, logged %.1f, balance %+.1f; want 56, 46, -10", total.Expected, total.Logged, total.Balance)
+	}
+	if pct, ok := total.Percent(); !ok || !near(pct, 46.0/56*100) {
+		t.Errorf("total utilization %.1f%% (%v), want %.1f%%", pct, ok, 46.0/56*100)
+	}
+}
+
+// TestUtilizationWithoutTarget checks weeks of holidays have no utilization
+func TestUtilizationWithoutTarget(t *testing.T) {
+	cal := weekdays
+	cal.Holidays = map[string]bool{"2026-03-09": true, "2026-03-10": true}
+	start := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	u := report.BuildUtilization(nil, cal, start, start.AddDate(0, 0, 2).Add(-time.Second))
+	if len(u.Weeks) != 1 {
+		t.Fatalf("got %d weeks, want 1", len(u.Weeks))
+	}
+	if pct, ok := u.Weeks[0].Percent(); ok {
+		t.Errorf("utilization %.1f%% of nothing expected", pct)
+	}
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+	"github.com/Ma-Kas/paymostats/internal/cache"
+	"github.com/Ma-Kas/paymostats/internal/render"
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+var (
+	// budget flags
+	flagBudgetUsers string // whose time burns the budgets, like --users
+	flagBurnDays    int    // days the burn rate is measured over
+)
+
+var budgetCmd = &cobra.Command{
+	Use:   "budget [name|id] [flags]",
+	Short: "Show how much of each project's budget hours is used up",
+	Long: `List every project with budget hours in Paymo, with the hours logged on it
+over all time, what's left, the share burned and when the budget runs out at
+the recent burn rate. Overruns are shown in red.
+
+Budgets cover the whole team, so everyone's time is counted by default. That
+needs admin rights; other accounts only count their own time.`,
+	Example: `  paymostats budget
+  paymostats budget website
+  paymostats budget --burn-days 14 -o csv`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := render.ParseFormat(flagOutput)
+		if err != nil {
+			return err
+		}
+		if flagBurnDays < 1 {
+			return fmt.Errorf("--burn-days must be at least 1")
+		}
+
+		ctx, stop := interruptible(cmd.Context())
+		defer stop()
+
+		src, userID, err := connect(ctx)
+		if err != nil {
+			return err
+		}
+		projects, err := src.Projects(ctx)
+		if err != nil {
+			return fmt.Errorf("fetch projects: %w", err)
+		}
+		opts := reportOptions{format: format}
+		if len(args) == 1 {
+			p, err := findProject(projects, args[0])
+			if err != nil {
+				return err
+			}
+			projects = map[int]api.Project{p.ID: p}
+			opts.projectID = p.ID
+		}
+
+		opts.team, err = resolveTeam(ctx, src, userID, flagBudgetUsers)
+		if errors.Is(err, api.ErrForbidden) || errors.Is(err, cache.ErrNoData) {
+			fmt.Fprintln(os.Stderr, "Note: only your own time is counted, the team's needs admin rights")
+			opts.team, err = nil, nil
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		entries, err := fetchEntries(ctx, src, userID, time.Unix(0, 0), now, opts)
+		if err != nil {
+			return err
+		}
+		b := report.BuildBudget(entries, projects, now, time.Duration(flagBurnDays)*24*time.Hour)
+		b.Label = teamLabel("Project budgets", opts.team)
+		if s, ok := src.(staleSource); ok {
+			b.AsOf = s.AsOf()
+		}
+
+		if len(b.Rows) == 0 && format == render.Table {
+			fmt.Println("No project has budget hours set in Paymo")
+			return nil
+		}
+		return render.RenderBudget(os.Stdout, format, b)
+	},
+}
+
+func init() {
+	budgetCmd.Flags().StringVarP(&flagOutput, "output", "o", "table", "output format: table|json|csv|tsv|markdown|html")
+	budgetCmd.Flags().StringVar(&flagBudgetUsers, "users", "all", "whose time counts: all|<id,...>|<email,...> (all needs admin rights)")
+	budgetCmd.Flags().IntVar(&flagBurnDays, "burn-days", 28, "measure the burn rate over this many recent days")
+}
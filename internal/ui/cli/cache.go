@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Ma-Kas/paymostats/internal/cache"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local cache of Paymo entries and projects",
+	Long: `paymostats caches fetched entries and projects under your user cache directory,
+so repeated reports only fetch days that may have changed since the last sync.
+
+Use --no-cache on any report to bypass it.`,
+	Args: cobra.NoArgs,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete all cached Paymo data",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cache.Clear(); err != nil {
+			return fmt.Errorf("clear cache: %w", err)
+		}
+		fmt.Println("Cache cleared")
+		return nil
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheClearCmd)
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+	"github.com/Ma-Kas/paymostats/internal/config"
+)
+
+var (
+	// global flags, shared by every command that talks to Paymo
+	flagAPIURL  string
+	flagTimeout time.Duration
+	flagProxy   string
+	flagRecord  string
+	flagReplay  string
+
+	// client options derived from the global flags (see buildClientOptions)
+	clientOpts []api.Option
+)
+
+// buildClientOptions validates the global flags once per run and turns them
+// into api options for newClient
+func buildClientOptions() error {
+	opts := []api.Option{
+		api.WithBaseURL(config.ResolveApiURL(flagAPIURL)),
+		api.WithTimeout(flagTimeout),
+	}
+	if flagProxy != "" {
+		u, err := url.Parse(flagProxy)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("invalid --proxy %q, use e.g. http://proxy.example.com:8080", flagProxy)
+		}
+		opts = append(opts, api.WithProxy(u))
+	}
+
+	logger, err := newLogger()
+	if err != nil {
+		return err
+	}
+	if logger != nil {
+		opts = append(opts, api.WithLogger(logger))
+	}
+
+	switch {
+	case flagRecord != "" && flagReplay != "":
+		return fmt.Errorf("--record and --replay can't be combined")
+	case flagRecord != "":
+		opts = append(opts, api.WithRecording(flagRecord))
+	case flagReplay != "":
+		cassette, err := api.LoadCassette(flagReplay)
+		if err != nil {
+			return fmt.Errorf("load --replay cassette: %w", err)
+		}
+		opts = append(opts, api.WithReplay(cassette))
+	}
+	clientOpts = opts
+	return nil
+}
+
+// resolveApiKey is config.ResolveApiKey, except that replaying a cassette
+// needs no real key or Keychain (recorded responses don't depend on it)
+func resolveApiKey() (string, error) {
+	key, err := config.ResolveApiKey()
+	if flagReplay != "" && err != nil {
+		return "replay", nil
+	}
+	return key, err
+}
+
+// newClient creates an API client honouring the global flags
+func newClient(apiKey string) *api.Client {
+	return api.NewClient(apiKey, clientOpts...)
+}
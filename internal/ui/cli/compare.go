@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/render"
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+// comparison is the --compare setting: the period right before the
+// reported one (see window), or fixed dates
+type comparison struct {
+	previous   bool
+	start, end time.Time
+}
+
+// parseCompare parses "previous" or "YYYY-MM-DD:YYYY-MM-DD"; "" means no comparison
+func parseCompare(s string) (*comparison, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "":
+		return nil, nil
+	case "previous", "prev":
+		return &comparison{previous: true}, nil
+	}
+
+	from, to, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid --compare %q (use: previous or YYYY-MM-DD:YYYY-MM-DD)", s)
+	}
+	start, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --compare start date, use YYYY-MM-DD")
+	}
+	end, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --compare end date, use YYYY-MM-DD")
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("--compare end must be >= start")
+	}
+	// Like --end, the end date counts in full
+	return &comparison{start: start, end: endOfDay(end)}, nil
+}
+
+// window returns the (label, start, end) to compare the range start..end
+// against. Calendar periods (unit set, see parseRange) go back one unit, so
+// June is compared with May, and a period so far with as much of the one
+// before; other ranges with the same length right before them
+func (c comparison) window(start, end time.Time, unit string) (string, time.Time, time.Time, error) {
+	if !c.previous {
+		return "Base period", c.start, c.end, nil
+	}
+	if start.Unix() == 0 {
+		return "", time.Time{}, time.Time{}, fmt.Errorf("all time has no previous period to compare with")
+	}
+	// The base ends the second before the range starts, so the two never
+	// share an entry
+	baseEnd := start.Add(-time.Second)
+	if unit == "" {
+		return "Previous period", start.Add(-end.Sub(start)), baseEnd, nil
+	}
+	if end.Before(shift(unit, start, 1).Add(-time.Second)) {
+		// Months differ in length, so a shifted end may spill over
+		if e := shift(unit, end, -1); e.Before(baseEnd) {
+			baseEnd = e
+		}
+	}
+	return "Previous " + unit, shift(unit, start, -1), baseEnd, nil
+}
+
+// runCompare reports two periods side by side, grouped by the single --group-by dimension
+func runCompare(ctx context.Context, src dataSource, userID int, label string, start, end time.Time, opts reportOptions) error {
+	baseLabel, baseStart, baseEnd, err := opts.compare.window(start, end, opts.period)
+	if err != nil {
+		return err
+	}
+	current, err := summarize(ctx, src, userID, label, start, end, opts)
+	if err != nil {
+		return err
+	}
+	base, err := summarize(ctx, src, userID, baseLabel, baseStart, baseEnd, opts)
+	if err != nil {
+		return err
+	}
+	return render.RenderComparison(os.Stdout, opts.format, report.Compare(current, base))
+}
+
+// summarize fetches and groups the entries of one period
+func summarize(ctx context.Context, src dataSource, userID int, label string, start, end time.Time, opts reportOptions) (report.Summary, error) {
+	summary := report.Summary{Label: label, Start: start, End: end, GroupBy: joinDimensions(opts.groupBy)}
+	entries, err := fetchEntries(ctx, src, userID, start, end, opts)
+	if err != nil {
+		return summary, err
+	}
+	summary.End = dataEnd(src, end)
+	if s, ok := src.(staleSource); ok {
+		summary.AsOf = s.AsOf()
+	}
+	if len(entries) == 0 {
+		return summary, nil
+	}
+	lk, err := lookupFor(ctx, src, opts)
+	if err != nil {
+		return summary, err
+	}
+	entries = filterBillable(entries, lk, opts)
+	fillSummary(&summary, entries, lk, opts)
+	return summary, nil
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCompare(t *testing.T) {
+	tests := []struct {
+		in         string
+		start, end string // RFC 3339; empty for "previous"
+		wantErr    bool
+	}{
+		{in: "previous"},
+		{in: "2026-03-01:2026-03-31", start: "2026-03-01T00:00:00Z", end: "2026-03-31T23:59:59Z"},
+		{in: "2026-03-05:2026-03-05", start: "2026-03-05T00:00:00Z", end: "2026-03-05T23:59:59Z"},
+		{in: "2026-03-31:2026-03-01", wantErr: true},
+		{in: "2026-03-01", wantErr: true},
+		{in: "2026-03-01:tomorrow", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			c, err := parseCompare(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil || tt.start == "" {
+				return
+			}
+			if got := c.start.Format(time.RFC3339); got != tt.start {
+				t.Errorf("start %s, want %s", got, tt.start)
+			}
+			if got := c.end.Format(time.RFC3339); got != tt.end {
+				t.Errorf("end %s, want %s", got, tt.end)
+			}
+		})
+	}
+}
+
+// TestEndFlagInclusive checks --end counts the whole day, like --compare
+func TestEndFlagInclusive(t *testing.T) {
+	_, _, end, _, err := computeRangeFromFlags("", "2026-03-01", "2026-03-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 3, 31, 23, 59, 59, 0, time.UTC); !end.Equal(want) {
+		t.Errorf("end %s, want %s", end, want)
+	}
+	if _, _, _, _, err := computeRangeFromFlags("", "2026-03-02", "2026-03-01"); err == nil {
+		t.Error("an end before the start was accepted")
+	}
+}
+
+func TestCompareWindow(t *testing.T) {
+	now := time.Date(2026, 3, 31, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		rng        string
+		start, end string // RFC 3339
+	}{
+		{"2025-06", "2025-05-01T00:00:00Z", "2025-05-31T23:59:59Z"},
+		{"2025-07", "2025-06-01T00:00:00Z", "2025-06-30T23:59:59Z"},
+		{"q1-2025", "2024-10-01T00:00:00Z", "2024-12-31T23:59:59Z"},
+		{"2025-W01", "2024-12-23T00:00:00Z", "2024-12-29T23:59:59Z"},
+		{"2025", "2024-01-01T00:00:00Z", "2024-12-31T23:59:59Z"},
+		{"yesterday", "2026-03-29T00:00:00Z", "2026-03-29T23:59:59Z"},
+		{"today", "2026-03-30T00:00:00Z", "2026-03-30T15:00:00Z"},
+		// So far: as much of the previous period, cut at its end
+		{"this-month", "2026-02-01T00:00:00Z", "2026-02-28T23:59:59Z"},
+		{"this-week", "2026-03-23T00:00:00Z", "2026-03-24T15:00:00Z"},
+		// Rolling windows: the same length before
+		{"10d", "2026-03-11T15:00:00Z", "2026-03-21T14:59:59Z"},
+	}
+	c := comparison{previous: true}
+	for _, tt := range tests {
+		t.Run(tt.rng, func(t *testing.T) {
+			_, start, end, unit, err := parseRange(tt.rng, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, baseStart, baseEnd, err := c.window(start, end, unit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := baseStart.Format(time.RFC3339); got != tt.start {
+				t.Errorf("start %s, want %s", got, tt.start)
+			}
+			if got := baseEnd.Format(time.RFC3339); got != tt.end {
+				t.Errorf("end %s, want %s", got, tt.end)
+			}
+		})
+	}
+}
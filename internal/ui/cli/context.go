@@ -0,0 +1,14 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/signal"
+)
+
+// interruptible derives a context that Ctrl-C cancels, so a hung fetch can be
+// aborted without killing the process. Call stop as soon as the work is done to
+// restore the default Ctrl-C behaviour (e.g. while waiting at a prompt)
+func interruptible(parent context.Context) (ctx context.Context, stop context.CancelFunc) {
+	return signal.NotifyContext(parent, os.Interrupt)
+}
@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+)
+
+// printError reports an error to the user, adding a hint for failures they can act on
+func printError(err error) {
+	if errors.Is(err, context.Canceled) {
+		fmt.Fprintln(os.Stderr, "Canceled")
+		return
+	}
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	if errors.Is(err, api.ErrRateLimited) {
+		fmt.Fprintln(os.Stderr, "Paymo is throttling requests from your account, wait a minute and try again")
+	}
+	if errors.Is(err, api.ErrForbidden) {
+		fmt.Fprintln(os.Stderr, "Your Paymo account isn't allowed to see this; reports on other users (--users) need admin rights")
+	}
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+	"github.com/Ma-Kas/paymostats/internal/query"
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+var (
+	// entry filter flags, each repeatable
+	flagProjects []string // ID, name, glob or /regex/
+	flagClients  []string
+	flagTasks    []string
+	flagSearch   string // text in the entry description
+	flagWhere    string // query expression, see package query
+)
+
+// entryFilter narrows down a report's entries: Match is the full check,
+// EntryFilter the part of it Paymo can apply
+type entryFilter interface {
+	Match(e api.TimeEntry) bool
+	EntryFilter() api.EntryFilter
+}
+
+// whereFilter is a --where expression with the names it's evaluated against
+type whereFilter struct {
+	q  *query.Query
+	lk report.Lookup
+}
+
+func (w whereFilter) Match(e api.TimeEntry) bool   { return w.q.Match(e, w.lk) }
+func (w whereFilter) EntryFilter() api.EntryFilter { return w.q.EntryFilter(w.lk) }
+
+// filteringSource is implemented by sources that can have Paymo filter the
+// entries: the API client, and the cache when it has to fetch. Entries the
+// cache already holds come unfiltered and are filtered locally
+type filteringSource interface {
+	FilteredEntries(ctx context.Context, userID int, start, end time.Time, f api.EntryFilter) ([]api.TimeEntry, error)
+}
+
+// parseFilter validates --project, --client, --task and --search
+func parseFilter() (report.Filter, error) {
+	var f report.Filter
+	for _, l := range []struct {
+		flag     string
+		values   []string
+		patterns *[]report.Pattern
+	}{{"--project", flagProjects, &f.Projects}, {"--client", flagClients, &f.Clients}, {"--task", flagTasks, &f.Tasks}} {
+		for _, v := range l.values {
+			p, err := report.ParsePattern(v)
+			if err != nil {
+				return f, fmt.Errorf("invalid %s: %w", l.flag, err)
+			}
+			*l.patterns = append(*l.patterns, p)
+		}
+	}
+	f.Search = flagSearch
+	return f, nil
+}
+
+// parseWhere parses --where, nil if it isn't set. Errors point at the column
+func parseWhere() (*query.Query, error) {
+	if flagWhere == "" {
+		return nil, nil
+	}
+	q, err := query.Parse(flagWhere)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --where: %w", err)
+	}
+	return q, nil
+}
+
+// resolveFilter matches the filter's patterns against Paymo's projects,
+// clients and tasks, and sets up --where with them. nil if there is nothing
+// to filter
+func resolveFilter(ctx context.Context, src dataSource, opts reportOptions) ([]entryFilter, error) {
+	f := opts.filter
+	if f.IsZero() && opts.where == nil {
+		return nil, nil
+	}
+	var lk report.Lookup
+	var err error
+	if lk.Projects, err = src.Projects(ctx); err != nil {
+		return nil, fmt.Errorf("fetch projects: %w", err)
+	}
+	// --where may use any name, and tasks tell whether entries are billable
+	if len(f.Clients) > 0 || opts.where != nil {
+		if lk.Clients, err = src.Clients(ctx); err != nil {
+			return nil, fmt.Errorf("fetch clients: %w", err)
+		}
+	}
+	if len(f.Tasks) > 0 || opts.where != nil {
+		if lk.Tasks, err = src.Tasks(ctx); err != nil {
+			return nil, fmt.Errorf("fetch tasks: %w", err)
+		}
+	}
+
+	var filters []entryFilter
+	if !f.IsZero() {
+		sel, err := f.Select(lk)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, sel)
+	}
+	if opts.where != nil {
+		filters = append(filters, whereFilter{opts.where, lk})
+	}
+	return filters, nil
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+)
+
+var (
+	// global debugging flags
+	flagDebug     bool
+	flagTrace     bool
+	flagDebugFile string
+)
+
+// newLogger returns the HTTP trace logger requested by --debug/--trace, or
+// nil if tracing is off. Logs go to stderr unless --debug-file is set
+func newLogger() (*slog.Logger, error) {
+	if !flagDebug && !flagTrace {
+		if flagDebugFile != "" {
+			return nil, fmt.Errorf("--debug-file requires --debug or --trace")
+		}
+		return nil, nil
+	}
+
+	level := slog.LevelDebug
+	if flagTrace {
+		level = api.LevelTrace
+	}
+
+	var w io.Writer = os.Stderr
+	if flagDebugFile != "" {
+		f, err := os.OpenFile(flagDebugFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("open --debug-file: %w", err)
+		}
+		// Left open for the lifetime of the process
+		w = f
+	}
+
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.LevelKey && a.Value.Any() == api.LevelTrace {
+				a.Value = slog.StringValue("TRACE")
+			}
+			return a
+		},
+	})), nil
+}
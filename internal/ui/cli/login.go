@@ -32,8 +32,8 @@ If not provided, you'll be prompted interactively.`,
 		// Fast path: flag provided -> validate -> overwrite without prompting
 		if strings.TrimSpace(loginAPIKey) != "" {
 			key := strings.TrimSpace(loginAPIKey)
-			client := api.NewClient(key)
-			if _, err := client.Me(); err != nil {
+			client := newClient(key)
+			if _, err := client.Me(cmd.Context()); err != nil {
 				if errors.Is(err, api.ErrUnauthorized) {
 					return fmt.Errorf("the provided API key is invalid (401)")
 				}
@@ -85,8 +85,8 @@ If not provided, you'll be prompted interactively.`,
 
 			}
 
-			client := api.NewClient(apiKey)
-			if _, err := client.Me(); err != nil {
+			client := newClient(apiKey)
+			if _, err := client.Me(cmd.Context()); err != nil {
 				if errors.Is(err, api.ErrUnauthorized) {
 					fmt.Printf("API key is invalid. Try again (%d/%d), or press ENTER to abort.\n", attempts, maxLoginAttempts)
 					continue
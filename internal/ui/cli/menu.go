@@ -2,49 +2,35 @@ package cli
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"math"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/Ma-Kas/paymostats/internal/api"
-	"github.com/Ma-Kas/paymostats/internal/config"
+	"github.com/Ma-Kas/paymostats/internal/cache"
+	"github.com/Ma-Kas/paymostats/internal/render"
 	"github.com/Ma-Kas/paymostats/internal/report"
-	"github.com/jedib0t/go-pretty/v6/table"
-	"github.com/jedib0t/go-pretty/v6/text"
 )
 
-func runMenu() error {
-	apiKey, err := config.ResolveApiKey()
-	if err == config.ErrNoApiKey {
-		fmt.Println("No API key found, please run `paymostats login` first")
-		return nil
-	}
-	if err != nil {
-		return err
-	}
-	client := api.NewClient(apiKey)
-	// client.EnableDebug() // Uncomment for verbose HTTP dumps
-
-	userID, err := client.Me()
-	if err != nil {
-		fmt.Println("Failed to get user:", err)
-		return nil
-	}
-
+func runMenu(ctx context.Context, src dataSource, userID int, opts reportOptions) error {
 	reader := bufio.NewReader(os.Stdin)
 	for {
 		fmt.Println()
 		fmt.Println(strings.ToUpper("Display your Paymo stats"))
 		fmt.Println(strings.Repeat("=", 40))
-		fmt.Println("a) Last week")
-		fmt.Println("b) Last two weeks")
-		fmt.Println("c) Last month")
-		fmt.Println("d) Last 3 months")
-		fmt.Println("e) Last 6 months")
-		fmt.Println("f) Year to date")
-		fmt.Println("g) All time")
+		now := time.Now()
+		keys := slices.Sorted(maps.Keys(choices))
+		for _, key := range keys {
+			label, _, _, _, _ := parseRange(choices[key], now)
+			fmt.Printf("%s) %s\n", key, label)
+		}
+		fmt.Println("r) Other range, e.g. q2-2025, 2025-W14, since monday")
 		fmt.Println(strings.Repeat("-", 40))
 		fmt.Println("q) Quit")
 		fmt.Println(strings.Repeat("=", 40))
@@ -54,38 +40,72 @@ func runMenu() error {
 		if choice == "q" {
 			return nil
 		}
-		spec, ok := choices[choice]
+		expr, ok := choices[choice]
+		if choice == "r" {
+			fmt.Print("Range: ")
+			line, _ := reader.ReadString('\n')
+			expr, ok = strings.TrimSpace(line), true
+		}
 		if !ok {
 			fmt.Println("Unknown option, try again.")
 			continue
 		}
 
-		start, end := bounds(spec)
-		if err := runRange(client, userID, spec.label, start, end); err != nil {
-			fmt.Println("Error:", err)
+		label, start, end, unit, err := parseRange(expr, time.Now())
+		if err != nil {
+			printError(err)
+			continue
+		}
+		opts.period = unit
+		fetchCtx, stop := interruptible(ctx)
+		err = runRange(fetchCtx, src, userID, label, start, end, opts)
+		stop()
+		if err != nil {
+			printError(err)
 		}
 		fmt.Println()
 	}
 }
 
-func runRange(c *api.Client, userID int, label string, start, end time.Time) error {
-	entries, err := c.Entries(userID, start, end)
+func runRange(ctx context.Context, src dataSource, userID int, label string, start, end time.Time, opts reportOptions) error {
+	label = reportLabel(label, opts)
+	if opts.compare != nil {
+		return runCompare(ctx, src, userID, label, start, end, opts)
+	}
+	entries, err := fetchEntries(ctx, src, userID, start, end, opts)
 	if err != nil {
-		return fmt.Errorf("fetch entries: %w", err)
+		return err
+	}
+	end = dataEnd(src, end)
+	var lk report.Lookup
+	if len(entries) > 0 {
+		if lk, err = lookupFor(ctx, src, opts); err != nil {
+			return err
+		}
+		entries = filterBillable(entries, lk, opts)
 	}
+
+	summary := report.Summary{Label: label, Start: start, End: end, GroupBy: joinDimensions(opts.groupBy), ShowAmounts: opts.amounts}
+	if s, ok := src.(staleSource); ok {
+		summary.AsOf = s.AsOf()
+	}
+
 	if len(entries) == 0 {
+		// Machine readable formats still get a (empty) document
+		if opts.format != render.Table {
+			if opts.columns != "" {
+				return render.RenderMatrix(os.Stdout, opts.format, report.Matrix{
+					Label: label, Start: start, End: end, RowType: string(opts.groupBy[0]), ColumnType: opts.columns, AsOf: summary.AsOf,
+				})
+			}
+			return render.Render(os.Stdout, opts.format, summary)
+		}
 		fmt.Printf("No entries found for %s (%s to %s)\n",
 			label, start.Format("2006-01-02"), end.Format("2006-01-02"))
 		return nil
 	}
 
-	projects, err := c.Projects()
-	if err != nil {
-		return fmt.Errorf("fetch projects: %w", err)
-	}
-
 	// For "All Time" case, replace caption start date with earliest actual entry time
-	displayStart := start
 	if start.Unix() == 0 {
 		minTS := int64(math.MaxInt64)
 		for _, e := range entries {
@@ -101,36 +121,137 @@ func runRange(c *api.Client, userID int, label string, start, end time.Time) err
 			}
 		}
 		if minTS != int64(math.MaxInt64) {
-			displayStart = time.Unix(minTS, 0).UTC()
+			start = time.Unix(minTS, 0).UTC()
+			summary.Start = start
 		}
 	}
 
-	rows, totalHours := report.Build(entries, projects)
+	if opts.columns != "" {
+		m, err := report.BuildMatrix(entries, lk, opts.groupBy[0], opts.columns, start, end)
+		if err != nil {
+			return err
+		}
+		m.Label, m.AsOf = label, summary.AsOf
+		return render.RenderMatrix(os.Stdout, opts.format, m)
+	}
+	fillSummary(&summary, entries, lk, opts)
+	return render.Render(os.Stdout, opts.format, summary)
+}
 
-	// Build the table
-	tw := table.NewWriter()
-	tw.SetOutputMirror(os.Stdout)
-	tw.SetStyle(table.StyleLight)
-	tw.Style().Format.Header = text.FormatTitle
-	tw.SetTitle(fmt.Sprintf("%s\n%s to %s",
-		strings.ToUpper(label),
-		displayStart.Format("2006-01-02"),
-		end.Format("2006-01-02"),
-	))
+// fillSummary groups the entries into the summary's rows and totals
+func fillSummary(s *report.Summary, entries []api.TimeEntry, lk report.Lookup, opts reportOptions) {
+	rows, total := report.Group(entries, lk, opts.groupBy)
+	s.Rows, s.TotalHours, s.BillableHours = rows, total.Hours, total.BillableHours
+	s.Amounts, s.UnratedHours = total.Amounts, total.UnratedHours
+}
+
+// reportLabel adds the --users, entry and billable filters to the range label
+func reportLabel(label string, opts reportOptions) string {
+	label = teamLabel(label, opts.team)
+	if !opts.filter.IsZero() {
+		label += " - " + opts.filter.String()
+	}
+	if opts.where != nil {
+		label += " - where " + opts.where.String()
+	}
+	switch {
+	case opts.billable == nil:
+	case *opts.billable:
+		label += " - Billable only"
+	default:
+		label += " - Non-billable only"
+	}
+	return label
+}
+
+// filterBillable applies --billable-only and --non-billable-only
+func filterBillable(entries []api.TimeEntry, lk report.Lookup, opts reportOptions) []api.TimeEntry {
+	if opts.billable == nil {
+		return entries
+	}
+	return lk.FilterBillable(entries, *opts.billable)
+}
 
-	tw.AppendHeader(table.Row{strings.ToUpper("Project"), strings.ToUpper("Hours"), strings.ToUpper("Percent")})
-	for _, r := range rows {
-		tw.AppendRow(table.Row{r.Name, fmt.Sprintf("%.1f", r.Hours), fmt.Sprintf("%.1f%%", r.Percent)})
+// fetchEntries fetches the entries of a period, for the caller or the
+// --users team, limited to the drilled down project and the entry filters if
+// any. Filters go into the request where the source supports it, and are
+// checked locally either way so both give the same entries
+func fetchEntries(ctx context.Context, src dataSource, userID int, start, end time.Time, opts reportOptions) ([]api.TimeEntry, error) {
+	fetch := src.Entries
+	if fs, ok := src.(filteringSource); ok && len(opts.filters) > 0 {
+		var f api.EntryFilter
+		for _, ef := range opts.filters {
+			f = f.And(ef.EntryFilter())
+		}
+		fetch = func(ctx context.Context, userID int, start, end time.Time) ([]api.TimeEntry, error) {
+			return fs.FilteredEntries(ctx, userID, start, end, f)
+		}
 	}
 
-	tw.AppendSeparator()
+	var entries []api.TimeEntry
+	if len(opts.team) == 0 {
+		var err error
+		if entries, err = fetch(ctx, userID, start, end); err != nil {
+			return nil, fmt.Errorf("fetch entries: %w", err)
+		}
+	}
+	for _, u := range opts.team {
+		userEntries, err := fetch(ctx, u.ID, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("fetch entries of %s: %w", userName(u), err)
+		}
+		entries = append(entries, userEntries...)
+	}
+	if opts.projectID != 0 {
+		entries = slices.DeleteFunc(entries, func(e api.TimeEntry) bool { return e.ProjectID != opts.projectID })
+	}
+	for _, f := range opts.filters {
+		entries = slices.DeleteFunc(entries, func(e api.TimeEntry) bool { return !f.Match(e) })
+	}
+	return entries, nil
+}
 
-	pctSum := 0.0
-	for _, r := range rows {
-		pctSum += r.Percent
+// lookupFor fetches the names the report's dimensions need. Projects and
+// tasks are always fetched: every dimension but time resolves through
+// projects, and both tell which entries are billable
+func lookupFor(ctx context.Context, src dataSource, opts reportOptions) (report.Lookup, error) {
+	dims := opts.groupBy
+	if opts.columns != "" {
+		dims = append(slices.Clone(dims), opts.columns)
+	}
+	lk := report.Lookup{Users: teamMap(opts.team)}
+	var err error
+	if lk.Projects, err = src.Projects(ctx); err != nil {
+		return lk, fmt.Errorf("fetch projects: %w", err)
+	}
+	if slices.Contains(dims, report.Client) {
+		if lk.Clients, err = src.Clients(ctx); err != nil {
+			return lk, fmt.Errorf("fetch clients: %w", err)
+		}
 	}
-	tw.AppendFooter(table.Row{"", fmt.Sprintf("%.1f hrs", totalHours), fmt.Sprintf("%.1f%%", pctSum)})
+	if lk.Tasks, err = src.Tasks(ctx); err != nil {
+		return lk, fmt.Errorf("fetch tasks: %w", err)
+	}
+	// User rates are the last fallback for amounts. Only admins may list
+	// users, so without them amounts just go without user rates
+	if opts.amounts && lk.Users == nil {
+		lk.Users, err = src.Users(ctx)
+		if err != nil && !errors.Is(err, api.ErrForbidden) && !errors.Is(err, cache.ErrNoData) {
+			return lk, fmt.Errorf("fetch users: %w", err)
+		}
+	}
+	if slices.Contains(dims, report.Task) {
+		if lk.TaskLists, err = src.TaskLists(ctx); err != nil {
+			return lk, fmt.Errorf("fetch tasklists: %w", err)
+		}
+	}
+	return lk, nil
+}
 
-	tw.Render()
-	return nil
+func joinDimensions(dims []report.Dimension) string {
+	names := make([]string, len(dims))
+	for i, d := range dims {
+		names[i] = string(d)
+	}
+	return strings.Join(names, ",")
 }
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+	"github.com/Ma-Kas/paymostats/internal/api/paymotest"
+	"github.com/Ma-Kas/paymostats/internal/cache"
+	"github.com/Ma-Kas/paymostats/internal/render"
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+// captureStdout returns what f prints to stdout
+func captureStdout(t *testing.T, f func() error) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan []byte)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- b
+	}()
+	ferr := f()
+	w.Close()
+	if ferr != nil {
+		t.Fatalf("unexpected error: %v", ferr)
+	}
+	return string(<-out)
+}
+
+func TestRunRange(t *testing.T) {
+	now := time.Date(2026, 3, 20, 18, 0, 0, 0, time.UTC)
+	fixtures := paymotest.Demo(now)
+	srv := paymotest.NewServer(&paymotest.Fake{Fixtures: fixtures})
+	defer srv.Close()
+	src := api.NewClient("demo", api.WithBaseURL(srv.URL))
+
+	start, end := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 6, 23, 59, 59, 0, time.UTC)
+	hours := func(keep func(paymotest.Entry) bool) float64 {
+		var h float64
+		for _, e := range fixtures.Entries {
+			if !e.Start.Before(start) && !e.Start.After(end) && keep(e) {
+				h += e.Duration.Hours()
+			}
+		}
+		return h
+	}
+	all := func(paymotest.Entry) bool { return true }
+
+	tests := []struct {
+		name      string
+		opts      reportOptions
+		wantHours float64
+	}{
+		{
+			name:      "own time",
+			opts:      reportOptions{format: render.JSON, groupBy: []report.Dimension{report.Project}},
+			wantHours: hours(func(e paymotest.Entry) bool { return e.UserID == 1 }),
+		},
+		{
+			name: "team",
+			opts: reportOptions{format: render.JSON, groupBy: []report.Dimension{report.User},
+				team: []api.User{{ID: 1}, {ID: 2}, {ID: 3}}},
+			wantHours: hours(all),
+		},
+		{
+			name: "one project",
+			opts: reportOptions{format: render.JSON, groupBy: []report.Dimension{report.Task}, projectID: 102},
+			wantHours: hours(func(e paymotest.Entry) bool {
+				return e.UserID == 1 && e.ProjectID == 102
+			}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := captureStdout(t, func() error {
+				return runRange(context.Background(), src, 1, "Test", start, end, tt.opts)
+			})
+			var doc struct {
+				Label      string  `json:"label"`
+				Start      string  `json:"start"`
+				End        string  `json:"end"`
+				TotalHours float64 `json:"total_hours"`
+			}
+			if err := json.NewDecoder(bytes.NewBufferString(out)).Decode(&doc); err != nil {
+				t.Fatalf("decode output: %v\n%s", err, out)
+			}
+			if doc.Start != "2026-03-02" || doc.End != "2026-03-06" {
+				t.Errorf("range %s to %s, want 2026-03-02 to 2026-03-06", doc.Start, doc.End)
+			}
+			if tt.wantHours == 0 {
+				t.Fatal("fixtures have no matching entries, the test checks nothing")
+			}
+			if math.Abs(doc.TotalHours-tt.wantHours) > 1e-6 {
+				t.Errorf("total %.2f hours, want %.2f", doc.TotalHours, tt.wantHours)
+			}
+		})
+	}
+}
+
+// TestRunRangeOffline checks offline reports print the range their data
+// covers, which ends at the last sync
+func TestRunRangeOffline(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	now := time.Now().UTC()
+	srv := paymotest.NewServer(&paymotest.Fake{Fixtures: paymotest.Demo(now)})
+	defer srv.Close()
+
+	store, err := cache.Open(srv.URL)
+	if err != nil {
+		t.Fatalf("open cache: %v", err)
+	}
+	if err := store.SaveMe(1); err != nil {
+		t.Fatalf("save user: %v", err)
+	}
+	opts := reportOptions{format: render.JSON, groupBy: []report.Dimension{report.Project}}
+	start := now.AddDate(0, 0, -5)
+	online := cache.NewSource(api.NewClient("demo", api.WithBaseURL(srv.URL)), store, 1)
+	captureStdout(t, func() error { return runRange(context.Background(), online, 1, "Test", start, now, opts) })
+
+	offline, _, err := cache.NewOffline(store)
+	if err != nil {
+		t.Fatalf("offline source: %v", err)
+	}
+	out := captureStdout(t, func() error {
+		return runRange(context.Background(), offline, 1, "Test", start, now.AddDate(0, 0, 3), opts)
+	})
+	var doc struct {
+		End string `json:"end"`
+	}
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if want := time.Now().UTC().Format("2006-01-02"); doc.End != want {
+		t.Errorf("offline report ends %s, want the last sync on %s", doc.End, want)
+	}
+}
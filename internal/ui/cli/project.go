@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+var projectCmd = &cobra.Command{
+	Use:   "project <name|id> [flags]",
+	Short: "Show which tasks of a project the time went into",
+	Long: `Break a single project down by task, with hours and each task's share of the project total.
+
+The project is matched by ID, exact name (case insensitive) or a unique part of its name.
+Without range flags, all time is shown. With --bucket, each task's hours are
+spread over days, weeks or months.`,
+	Example: `  paymostats project "Website Relaunch"
+  paymostats project website --range 3m
+  paymostats project 1234 --start 2025-01-01 -o csv
+  paymostats project website --range 3m --bucket week
+  paymostats project website --range month --compare previous
+  paymostats project website --users all --pivot user`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := reportOptionsFromFlags()
+		if err != nil {
+			return err
+		}
+		rng := flagRange
+		if rng == "" && flagStart == "" && flagEnd == "" {
+			rng = "all"
+		}
+		label, start, end, unit, err := computeRangeFromFlags(rng, flagStart, flagEnd)
+		if err != nil {
+			return err
+		}
+		opts.period = unit
+
+		ctx, stop := interruptible(cmd.Context())
+		defer stop()
+
+		src, userID, err := connect(ctx)
+		if err != nil {
+			return err
+		}
+		projects, err := src.Projects(ctx)
+		if err != nil {
+			return fmt.Errorf("fetch projects: %w", err)
+		}
+		p, err := findProject(projects, args[0])
+		if err != nil {
+			return err
+		}
+
+		// The task breakdown replaces the root --group-by
+		opts.groupBy = []report.Dimension{report.Task}
+		opts.projectID = p.ID
+		if opts.team, err = resolveTeam(ctx, src, userID, flagUsers); err != nil {
+			return err
+		}
+		if opts.filters, err = resolveFilter(ctx, src, opts); err != nil {
+			return err
+		}
+		return runRange(ctx, src, userID, p.Name+" - "+label, start, end, opts)
+	},
+}
+
+// findProject resolves a project by ID, exact name or unique name fragment
+func findProject(projects map[int]api.Project, query string) (api.Project, error) {
+	if id, err := strconv.Atoi(query); err == nil {
+		if p, ok := projects[id]; ok {
+			return p, nil
+		}
+	}
+
+	q := strings.ToLower(strings.TrimSpace(query))
+	var partial []api.Project
+	for _, p := range projects {
+		name := strings.ToLower(p.Name)
+		if name == q {
+			return p, nil
+		}
+		if strings.Contains(name, q) {
+			partial = append(partial, p)
+		}
+	}
+
+	switch len(partial) {
+	case 0:
+		return api.Project{}, fmt.Errorf("no project matches %q", query)
+	case 1:
+		return partial[0], nil
+	default:
+		names := make([]string, len(partial))
+		for i, p := range partial {
+			names[i] = fmt.Sprintf("%s (%d)", p.Name, p.ID)
+		}
+		sort.Strings(names)
+		return api.Project{}, fmt.Errorf("%q matches several projects: %s", query, strings.Join(names, ", "))
+	}
+}
+
+func init() {
+	// Same range and output flags as the root command
+	projectCmd.Flags().StringVarP(&flagRange, "range", "r", "", "range, e.g. month|3m|ytd|all, last-month, q2-2025, since monday (default all)")
+	projectCmd.Flags().StringVarP(&flagStart, "start", "s", "", "start date (YYYY-MM-DD)")
+	projectCmd.Flags().StringVarP(&flagEnd, "end", "e", "", "end date (YYYY-MM-DD)")
+	projectCmd.Flags().StringVarP(&flagOutput, "output", "o", "table", "output format: table|json|csv|tsv|markdown|html")
+	projectCmd.Flags().StringVar(&flagBucket, "bucket", "", "time series: hours per task per day|week|month")
+	projectCmd.Flags().StringVar(&flagPivot, "pivot", "", "cross tab: spread the tasks over columns of this dimension, e.g. user")
+	projectCmd.Flags().StringVar(&flagUsers, "users", "", "include other users' time: all|<id,...>|<email,...> (needs admin rights)")
+	projectCmd.Flags().BoolVar(&flagBillableOnly, "billable-only", false, "only count billable time")
+	projectCmd.Flags().BoolVar(&flagNonBillableOnly, "non-billable-only", false, "only count non-billable time")
+	projectCmd.Flags().BoolVar(&flagAmounts, "amounts", false, "add amounts (hours x hourly rate) per currency")
+	projectCmd.Flags().StringArrayVar(&flagTasks, "task", nil, "only count these tasks: ID, name, glob (review*) or /regex/; repeatable")
+	projectCmd.Flags().StringVar(&flagSearch, "search", "", "only count entries whose description contains this text")
+	projectCmd.Flags().StringVar(&flagWhere, "where", "", `only count entries matching this expression, e.g. 'task ~ "review*" and hours > 1'`)
+	projectCmd.Flags().StringVar(&flagCompare, "compare", "", "compare tasks with the previous period (e.g. the month before a month), or YYYY-MM-DD:YYYY-MM-DD")
+}
@@ -1,37 +1,264 @@
 package cli
 
-import "time"
-
-type rangeSpec struct {
-	label  string
-	days   int // 0 = all time
-	custom func() (time.Time, time.Time)
-}
-
-var choices = map[string]rangeSpec{
-	"a": {label: "Last week", days: 7},
-	"b": {label: "Last two weeks", days: 14},
-	"c": {label: "Last month", days: 30},
-	"d": {label: "Last 3 months", days: 90},
-	"e": {label: "Last 6 months", days: 180},
-	"f": {
-		label: "Year to date",
-		custom: func() (time.Time, time.Time) {
-			now := time.Now()
-			start := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
-			return start, now
-		},
-	},
-	"g": {label: "All Time", days: 0},
-}
-
-func bounds(spec rangeSpec) (time.Time, time.Time) {
-	if spec.custom != nil {
-		return spec.custom()
-	}
-	end := time.Now().UTC()
-	if spec.days == 0 {
-		return time.Unix(0, 0), end
-	}
-	return end.AddDate(0, 0, -spec.days), end
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// choices maps the menu keys to range expressions, see parseRange. The
+// menu and --range share the expressions, so both give the same dates
+var choices = map[string]string{
+	"a": "week",
+	"b": "2w",
+	"c": "month",
+	"d": "3m",
+	"e": "6m",
+	"f": "ytd",
+	"g": "all",
+	"h": "this-week",
+	"i": "last-week",
+	"j": "last-month",
+	"k": "this-quarter",
+}
+
+// rangeExamples lists some expressions for help and error messages
+const rangeExamples = "week|2w|month|3m|6m|ytd|all, this-week, last-month, this-quarter, q2-2025, 2025-07, 2025-W14, 30d, yesterday, since monday, 3 weeks ago"
+
+var (
+	reRolling = regexp.MustCompile(`^(\d+)\s*([dwmy])$`)
+	reQuarter = regexp.MustCompile(`^q([1-4])[- ]?(\d{4})$|^(\d{4})[- ]?q([1-4])$`)
+	reISOWeek = regexp.MustCompile(`^(\d{4})-?w(\d{1,2})$`)
+	reMonth   = regexp.MustCompile(`^(\d{4})-(\d{2})$`)
+	reDay     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	reYear    = regexp.MustCompile(`^\d{4}$`)
+)
+
+// units are the calendar periods expressions can name
+var units = map[string]string{
+	"day": "day", "days": "day",
+	"week": "week", "weeks": "week",
+	"month": "month", "months": "month",
+	"quarter": "quarter", "quarters": "quarter",
+	"year": "year", "years": "year",
+}
+
+// weekdays maps weekday names, short or in full, for "since monday" ranges
+// and the utilization config's workdays
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+// parseRange resolves a range expression relative to now, in UTC:
+//
+//   - rolling windows ending now: week, 2w, month, 3m, 6m, 30d, 1y, last 10 days
+//   - calendar periods so far: today, this-week, this-month, this-quarter, this-year, ytd
+//   - whole calendar periods: yesterday, last-week, last-month, last-quarter,
+//     3 weeks ago, q2-2025, 2025-07, 2025-W14 (ISO week), 2025, 2025-07-14
+//   - since monday (the latest one, today included), since 2025-07-01,
+//     since last-month (any expression's start)
+//   - all
+//
+// Weeks start on Monday. Periods reaching past now end now. Besides the
+// label and dates it returns the unit of calendar periods, whole or so far
+// (e.g. "month" for 2025-07 and this-month), and "" for other ranges
+func parseRange(expr string, now time.Time) (string, time.Time, time.Time, string, error) {
+	now = now.UTC()
+	s := strings.Join(strings.Fields(strings.ToLower(expr)), " ")
+
+	// The fixed ranges from before there were expressions
+	switch s {
+	case "":
+		return "", time.Time{}, time.Time{}, "", fmt.Errorf("empty range (e.g. %s)", rangeExamples)
+	case "week", "1w":
+		return "Last week", now.AddDate(0, 0, -7), now, "", nil
+	case "2w", "two-weeks", "last-2-weeks":
+		return "Last two weeks", now.AddDate(0, 0, -14), now, "", nil
+	case "month", "1m":
+		return "Last month", now.AddDate(0, -1, 0), now, "", nil
+	case "3m", "quarter", "last-3-months":
+		return "Last 3 months", now.AddDate(0, -3, 0), now, "", nil
+	case "6m", "last-6-months":
+		return "Last 6 months", now.AddDate(0, -6, 0), now, "", nil
+	case "ytd", "year-to-date":
+		return "Year to date", periodStart("year", now), now, "year", nil
+	case "all", "forever":
+		return "All time", time.Unix(0, 0).UTC(), now, "", nil
+	case "today":
+		return "Today", periodStart("day", now), now, "day", nil
+	case "yesterday":
+		return periodAgo("day", 1, now, "Yesterday")
+	}
+
+	if rest, ok := strings.CutPrefix(s, "since "); ok {
+		return since(rest, now)
+	}
+
+	if m := reRolling.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		unit := map[string]string{"d": "day", "w": "week", "m": "month", "y": "year"}[m[2]]
+		return rolling(n, unit, now)
+	}
+
+	// Absolute periods
+	if m := reQuarter.FindStringSubmatch(s); m != nil {
+		q, year := m[1], m[2]
+		if q == "" {
+			q, year = m[4], m[3]
+		}
+		n, _ := strconv.Atoi(q)
+		y, _ := strconv.Atoi(year)
+		start := time.Date(y, time.Month(3*n-2), 1, 0, 0, 0, 0, time.UTC)
+		return period(fmt.Sprintf("Q%d %d", n, y), "quarter", start, now)
+	}
+	if m := reISOWeek.FindStringSubmatch(s); m != nil {
+		y, _ := strconv.Atoi(m[1])
+		w, _ := strconv.Atoi(m[2])
+		// Week 1 is the one with January 4th in it
+		start := periodStart("week", time.Date(y, 1, 4, 0, 0, 0, 0, time.UTC)).AddDate(0, 0, 7*(w-1))
+		if gy, gw := start.ISOWeek(); w < 1 || gy != y || gw != w {
+			return "", time.Time{}, time.Time{}, "", fmt.Errorf("%d has no week %d", y, w)
+		}
+		return period(fmt.Sprintf("Week %d-W%02d", y, w), "week", start, now)
+	}
+	if m := reMonth.FindStringSubmatch(s); m != nil {
+		start, err := time.Parse("2006-01", s)
+		if err != nil {
+			return "", time.Time{}, time.Time{}, "", fmt.Errorf("invalid month %q, use YYYY-MM", expr)
+		}
+		return period(start.Format("January 2006"), "month", start, now)
+	}
+	if reDay.MatchString(s) {
+		start, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return "", time.Time{}, time.Time{}, "", fmt.Errorf("invalid date %q, use YYYY-MM-DD", expr)
+		}
+		return period(start.Format("2006-01-02"), "day", start, now)
+	}
+	if reYear.MatchString(s) {
+		y, _ := strconv.Atoi(s)
+		return period(s, "year", time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC), now)
+	}
+
+	// Relative periods: this-week, last month, 3 weeks ago, last 10 days
+	words := strings.Fields(strings.ReplaceAll(s, "-", " "))
+	switch {
+	case len(words) == 2 && words[0] == "this" && units[words[1]] != "":
+		unit := units[words[1]]
+		return "This " + unit, periodStart(unit, now), now, unit, nil
+	case len(words) == 2 && (words[0] == "last" || words[0] == "previous") && units[words[1]] != "":
+		unit := units[words[1]]
+		return periodAgo(unit, 1, now, "Previous "+unit)
+	case len(words) == 3 && words[2] == "ago" && units[words[1]] != "":
+		n, err := strconv.Atoi(words[0])
+		if err != nil || n < 1 {
+			break
+		}
+		unit := units[words[1]]
+		return periodAgo(unit, n, now, fmt.Sprintf("%d %s ago", n, plural(n, unit)))
+	case len(words) == 3 && words[0] == "last" && units[words[2]] != "":
+		n, err := strconv.Atoi(words[1])
+		if err != nil || n < 1 {
+			break
+		}
+		return rolling(n, units[words[2]], now)
+	}
+	return "", time.Time{}, time.Time{}, "", fmt.Errorf("unknown range %q (e.g. %s)", expr, rangeExamples)
+}
+
+// rolling is the window of n units ending now
+func rolling(n int, unit string, now time.Time) (string, time.Time, time.Time, string, error) {
+	if n < 1 {
+		return "", time.Time{}, time.Time{}, "", fmt.Errorf("a range needs at least one %s", unit)
+	}
+	label := fmt.Sprintf("Last %d %s", n, plural(n, unit))
+	if n == 1 {
+		label = "Last " + unit
+	}
+	return label, shift(unit, now, -n), now, "", nil
+}
+
+// periodAgo is the whole calendar period n units before the current one
+func periodAgo(unit string, n int, now time.Time, label string) (string, time.Time, time.Time, string, error) {
+	return period(label, unit, shift(unit, periodStart(unit, now), -n), now)
+}
+
+// period is the calendar period of the unit from start, ending at its last
+// second or now, whichever is first
+func period(label, unit string, start, now time.Time) (string, time.Time, time.Time, string, error) {
+	if start.After(now) {
+		return "", time.Time{}, time.Time{}, "", fmt.Errorf("%s is in the future", label)
+	}
+	end := shift(unit, start, 1).Add(-time.Second)
+	if end.After(now) {
+		end = now
+	}
+	return label, start, end, unit, nil
+}
+
+// since runs from the latest of a weekday, or the start of any other
+// expression, until now
+func since(rest string, now time.Time) (string, time.Time, time.Time, string, error) {
+	if wd, ok := weekdays[rest]; ok {
+		today := periodStart("day", now)
+		start := today.AddDate(0, 0, -((int(today.Weekday()) - int(wd) + 7) % 7))
+		return "Since " + wd.String(), start, now, "", nil
+	}
+	_, start, _, _, err := parseRange(rest, now)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, "", err
+	}
+	return "Since " + start.Format("2006-01-02"), start, now, "", nil
+}
+
+// periodStart returns the start of the unit's calendar period containing t
+func periodStart(unit string, t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch unit {
+	case "week":
+		day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case "month":
+		return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	case "quarter":
+		return time.Date(y, m-(m-1)%3, 1, 0, 0, 0, 0, time.UTC)
+	case "year":
+		return time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// endOfDay returns the last second of t's day, so a date given as the end of
+// a range counts in full
+func endOfDay(t time.Time) time.Time {
+	return periodStart("day", t).AddDate(0, 0, 1).Add(-time.Second)
+}
+
+// shift moves t by n units
+func shift(unit string, t time.Time, n int) time.Time {
+	switch unit {
+	case "week":
+		return t.AddDate(0, 0, 7*n)
+	case "month":
+		return t.AddDate(0, n, 0)
+	case "quarter":
+		return t.AddDate(0, 3*n, 0)
+	case "year":
+		return t.AddDate(n, 0, 0)
+	default:
+		return t.AddDate(0, 0, n)
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return unit
+	}
+	return unit + "s"
 }
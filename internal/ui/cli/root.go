@@ -2,76 +2,185 @@ package cli
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"slices"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/Ma-Kas/paymostats/internal/api"
 	"github.com/Ma-Kas/paymostats/internal/config"
+	"github.com/Ma-Kas/paymostats/internal/query"
+	"github.com/Ma-Kas/paymostats/internal/render"
+	"github.com/Ma-Kas/paymostats/internal/report"
 )
 
 var (
 	// root flags
-	flagRange string // week|2w|month|3m|6m|ytd|all
-	flagStart string // YYYY-MM-DD
-	flagEnd   string // YYYY-MM-DD
+	flagRange   string // week|2w|month|3m|6m|ytd|all
+	flagStart   string // YYYY-MM-DD
+	flagEnd     string // YYYY-MM-DD
+	flagOutput  string // table|json|csv|tsv|markdown|html
+	flagGroupBy string // comma separated dimensions, e.g. client,project
+	flagNested  bool
+	flagBucket  string // day|week|month
+	flagCompare string // previous|YYYY-MM-DD:YYYY-MM-DD
+	flagPivot   string // a dimension to spread the rows over, e.g. project
+	flagUsers   string // all|<id,...>|<email,...>
+
+	flagBillableOnly    bool
+	flagNonBillableOnly bool
+	flagAmounts         bool
 )
 
-// computeRangeFromFlags returns (label, start, end) based on flags.
-// Date flags override --range if provided
-func computeRangeFromFlags(rng, startStr, endStr string) (string, time.Time, time.Time, error) {
+// reportOptions are the flag driven report settings, shared by the
+// non-interactive path and the menu
+type reportOptions struct {
+	format    render.Format
+	groupBy   []report.Dimension // outermost first
+	columns   report.Dimension   // matrix columns (--bucket or --pivot); empty for a plain summary
+	compare   *comparison        // period to compare against; nil for none
+	projectID int                // only report on this project (project drill-down); 0 = all
+	team      []api.User         // users to report on (--users); nil for just the caller
+	billable  *bool              // only billable (true) or non-billable (false) entries; nil for all
+	amounts   bool               // add money columns (billable hours x rate)
+	filter    report.Filter      // --project, --client, --task and --search
+	where     *query.Query       // --where; nil for none
+	filters   []entryFilter      // filter and where resolved by resolveFilter; nil for all entries
+	period    string             // calendar unit of the range, see parseRange; "" for other ranges
+}
+
+// reportOptionsFromFlags validates --output, --group-by, --nested, --bucket,
+// --pivot, --compare, --amounts, the billable and the entry filters and --where. --users
+// and the entry filters need the API and are resolved by resolveTeam and resolveFilter
+func reportOptionsFromFlags() (reportOptions, error) {
+	format, err := render.ParseFormat(flagOutput)
+	if err != nil {
+		return reportOptions{}, err
+	}
+	dims, err := report.ParseDimensions(flagGroupBy)
+	if err != nil {
+		return reportOptions{}, fmt.Errorf("invalid --group-by: %w", err)
+	}
+	// --nested is shorthand for a project level under a single client level
+	if flagNested {
+		if !slices.Equal(dims, []report.Dimension{report.Client}) {
+			return reportOptions{}, fmt.Errorf("--nested needs --group-by client (or use --group-by client,project)")
+		}
+		dims = append(dims, report.Project)
+	}
+	columns, err := parseColumns(flagBucket, flagPivot)
+	if err != nil {
+		return reportOptions{}, err
+	}
+	if columns != "" && len(dims) > 1 {
+		return reportOptions{}, fmt.Errorf("--bucket and --pivot take a single --group-by dimension for the rows")
+	}
+	compare, err := parseCompare(flagCompare)
+	if err != nil {
+		return reportOptions{}, err
+	}
+	if compare != nil {
+		switch {
+		case columns != "":
+			return reportOptions{}, fmt.Errorf("--compare can't be combined with --bucket or --pivot")
+		case len(dims) > 1:
+			return reportOptions{}, fmt.Errorf("--compare takes a single --group-by dimension")
+		case dims[0] == report.Day || dims[0] == report.Week || dims[0] == report.Month:
+			return reportOptions{}, fmt.Errorf("--compare needs a --group-by that both periods share, not %s", dims[0])
+		}
+	}
+	var billable *bool
+	switch {
+	case flagBillableOnly && flagNonBillableOnly:
+		return reportOptions{}, fmt.Errorf("--billable-only and --non-billable-only can't be combined")
+	case flagBillableOnly, flagNonBillableOnly:
+		b := flagBillableOnly
+		billable = &b
+	}
+	if flagAmounts && (columns != "" || compare != nil) {
+		return reportOptions{}, fmt.Errorf("--amounts can't be combined with --bucket, --pivot or --compare")
+	}
+	filter, err := parseFilter()
+	if err != nil {
+		return reportOptions{}, err
+	}
+	where, err := parseWhere()
+	if err != nil {
+		return reportOptions{}, err
+	}
+	return reportOptions{format: format, groupBy: dims, columns: columns, compare: compare, billable: billable, amounts: flagAmounts, filter: filter, where: where}, nil
+}
+
+// parseColumns returns the matrix column dimension from --bucket (time only)
+// or --pivot (any dimension), empty if neither is set
+func parseColumns(bucket, pivot string) (report.Dimension, error) {
+	if bucket != "" && pivot != "" {
+		return "", fmt.Errorf("--bucket and --pivot can't be combined")
+	}
+	if pivot != "" {
+		dims, err := report.ParseDimensions(pivot)
+		if err != nil {
+			return "", fmt.Errorf("invalid --pivot: %w", err)
+		}
+		if len(dims) > 1 {
+			return "", fmt.Errorf("--pivot takes a single dimension")
+		}
+		return dims[0], nil
+	}
+	switch b := report.Dimension(strings.ToLower(strings.TrimSpace(bucket))); b {
+	case "", report.Day, report.Week, report.Month:
+		return b, nil
+	default:
+		return "", fmt.Errorf("unknown --bucket %q (use: day|week|month)", bucket)
+	}
+}
+
+// computeRangeFromFlags returns (label, start, end, unit) based on flags,
+// see parseRange for the unit. Date flags override --range if provided
+func computeRangeFromFlags(rng, startStr, endStr string) (string, time.Time, time.Time, string, error) {
 	now := time.Now().UTC()
 
 	// Date flags override range
 	if startStr != "" || endStr != "" {
 		if startStr == "" {
-			return "", time.Time{}, time.Time{}, fmt.Errorf("--start is required when using --start/--end")
+			return "", time.Time{}, time.Time{}, "", fmt.Errorf("--start is required when using --start/--end")
 		}
 		start, err := time.Parse("2006-01-02", startStr)
 		if err != nil {
-			return "", time.Time{}, time.Time{}, fmt.Errorf("invalid --start date, use YYYY-MM-DD")
+			return "", time.Time{}, time.Time{}, "", fmt.Errorf("invalid --start date, use YYYY-MM-DD")
 		}
 		var end time.Time
 		if endStr == "" {
 			end = now
 		} else {
-			end, err = time.Parse("2006-01-02", endStr)
+			day, err := time.Parse("2006-01-02", endStr)
 			if err != nil {
-				return "", time.Time{}, time.Time{}, fmt.Errorf("invalid --end date, use YYYY-MM-DD")
+				return "", time.Time{}, time.Time{}, "", fmt.Errorf("invalid --end date, use YYYY-MM-DD")
 			}
+			end = endOfDay(day)
 		}
 		if end.Before(start) {
-			return "", time.Time{}, time.Time{}, fmt.Errorf("--end must be >= --start")
+			return "", time.Time{}, time.Time{}, "", fmt.Errorf("--end must be >= --start")
 		}
-		return "Custom", start, end, nil
-	}
-
-	// Predefined ranges
-	switch strings.ToLower(strings.TrimSpace(rng)) {
-	case "week", "1w", "last-week":
-		return "Last week", now.AddDate(0, 0, -7), now, nil
-	case "2w", "two-weeks", "last-2-weeks":
-		return "Last two weeks", now.AddDate(0, 0, -14), now, nil
-	case "month", "1m", "last-month":
-		return "Last month", now.AddDate(0, -1, 0), now, nil
-	case "3m", "quarter", "last-3-months":
-		return "Last 3 months", now.AddDate(0, -3, 0), now, nil
-	case "6m", "last-6-months":
-		return "Last 6 months", now.AddDate(0, -6, 0), now, nil
-	case "ytd", "year-to-date":
-		y := now.Year()
-		return "Year to date", time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC), now, nil
-	case "all", "forever":
-		return "All time", time.Unix(0, 0), now, nil
-	case "":
-		return "", time.Time{}, time.Time{}, fmt.Errorf("no flags passed; run with --range or --start/--end, or use interactive mode")
-	default:
-		return "", time.Time{}, time.Time{}, fmt.Errorf("unknown --range %q (use: week|2w|month|3m|6m|ytd|all)", rng)
+		return "Custom", start, end, "", nil
+	}
+
+	// Range expressions, the same the menu uses
+	if strings.TrimSpace(rng) == "" {
+		return "", time.Time{}, time.Time{}, "", fmt.Errorf("no flags passed; run with --range or --start/--end, or use interactive mode")
 	}
+	label, start, end, unit, err := parseRange(rng, now)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, "", fmt.Errorf("invalid --range: %w", err)
+	}
+	return label, start, end, unit, nil
 }
 
 var rootCmd = &cobra.Command{
@@ -81,22 +190,52 @@ var rootCmd = &cobra.Command{
 
 Use it interactively (no flags) or non-interactively with flags.
 
-- Predefined ranges: --range week|2w|month|3m|6m|ytd|all
-- Explicit dates:    --start YYYY-MM-DD [--end YYYY-MM-DD]`,
+- Ranges:            --range week|2w|month|3m|6m|ytd|all, or e.g. last-month, q2-2025, 2025-W14, since monday
+- Explicit dates:    --start YYYY-MM-DD [--end YYYY-MM-DD]
+- Output format:     --output table|json|csv|tsv|markdown|html
+- Grouping:          --group-by client,project (any of client|project|task|user|day|week|month|billable|tag)
+- Time series:       --bucket day|week|month (hours per group per period)
+- Comparison:        --compare previous|YYYY-MM-DD:YYYY-MM-DD
+- Billable time:     --billable-only | --non-billable-only
+- Money:             --amounts (billable hours x rate, per currency)
+- Cross tab:         --pivot project (e.g. with --group-by user)
+- Team reports:      --users all|<id,...>|<email,...> (needs Paymo admin rights)`,
 	Example: `  paymostats --range 2w
+  paymostats --range last-month
+  paymostats --range "3 weeks ago" --group-by day
   paymostats --start 2025-07-01 --end 2025-07-25
+  paymostats --range month -o csv > month.csv
+  paymostats --range 2w -o html > report.html
+  paymostats --range 3m --group-by client,project
+  paymostats --range ytd --group-by month,billable
+  paymostats --range 3m --bucket week -o csv > trend.csv
+  paymostats --range month --compare previous
+  paymostats --range month --amounts --billable-only
+  paymostats --range month --users all --group-by user --pivot project
   paymostats                 # interactive menu`,
 
 	Args: cobra.NoArgs,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return buildClientOptions()
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		// Offline mode never needs an API key or the network
+		if flagOffline {
+			src, userID, err := newOfflineSource()
+			if err != nil {
+				return err
+			}
+			return runReport(cmd.Context(), src, userID)
+		}
+
 		reader := bufio.NewReader(os.Stdin)
 
-		// Resolve apiKey (env/keychain handled in config.ResolveApiKey())
-		apiKey, err := config.ResolveApiKey()
+		// Resolve apiKey (env/keychain handled in resolveApiKey())
+		apiKey, err := resolveApiKey()
 		switch {
 		case err == config.ErrNoApiKey:
 			// If user passed flags but has no API key, don't go interactive
-			if flagRange != "" || flagStart != "" || flagEnd != "" {
+			if scripted() {
 				fmt.Println("No API key found. Run `paymostats login --api-key <YOUR_KEY>` first")
 				return nil
 			}
@@ -119,7 +258,7 @@ Use it interactively (no flags) or non-interactively with flags.
 
 			// Re-resolve after login; proceed only if present
 			var rerr error
-			apiKey, rerr = config.ResolveApiKey()
+			apiKey, rerr = resolveApiKey()
 			if rerr != nil {
 				return nil
 			}
@@ -129,10 +268,10 @@ Use it interactively (no flags) or non-interactively with flags.
 		}
 
 		// Validate API key; if invalid and flags were provided, suggest login & exit
-		client := api.NewClient(apiKey)
-		if _, err := client.Me(); err != nil {
+		client := newClient(apiKey)
+		if _, err := client.Me(cmd.Context()); err != nil {
 			if errors.Is(err, api.ErrUnauthorized) {
-				if flagRange != "" || flagStart != "" || flagEnd != "" {
+				if scripted() {
 					fmt.Println("Stored API key is invalid or expired. Run `paymostats login --api-key <NEW_KEY>` and try again")
 					return nil
 				}
@@ -155,12 +294,12 @@ Use it interactively (no flags) or non-interactively with flags.
 
 				// Re-resolve again after login - continue only if valid now
 				var rerr error
-				apiKey, rerr = config.ResolveApiKey()
+				apiKey, rerr = resolveApiKey()
 				if rerr != nil {
 					return nil
 				}
-				client = api.NewClient(apiKey)
-				if _, verr := client.Me(); verr != nil {
+				client = newClient(apiKey)
+				if _, verr := client.Me(cmd.Context()); verr != nil {
 					fmt.Println("Login didn't complete; try `paymostats login` again later.")
 					return nil
 				}
@@ -170,37 +309,107 @@ Use it interactively (no flags) or non-interactively with flags.
 		}
 
 		// Valid API key paths:
+		userID, err := client.Me(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		return runReport(cmd.Context(), newSource(client, userID), userID)
+	},
+}
 
-		// Non-interactive mode if any flags were set
-		if flagRange != "" || flagStart != "" || flagEnd != "" {
-			userID, err := client.Me()
-			if err != nil {
-				return fmt.Errorf("failed to get user: %w", err)
-			}
-			label, start, end, err := computeRangeFromFlags(flagRange, flagStart, flagEnd)
-			if err != nil {
-				return err
-			}
-			return runRange(client, userID, label, start, end)
+// rangeGiven tells whether the flags name a range, i.e. ask for a report
+// rather than the menu
+func rangeGiven() bool {
+	return flagRange != "" || flagStart != "" || flagEnd != ""
+}
+
+// scripted tells whether to avoid prompts: a range was given, or an output
+// format other than the table only scripts read
+func scripted() bool {
+	format, _ := render.ParseFormat(flagOutput)
+	return rangeGiven() || format != render.Table
+}
+
+// runReport renders the range given by flags (non-interactive mode), or
+// opens the interactive menu if no range flags were set
+func runReport(ctx context.Context, src dataSource, userID int) error {
+	opts, err := reportOptionsFromFlags()
+	if err != nil {
+		return err
+	}
+	ranged := rangeGiven()
+	// The menu is for the terminal and only renders tables, so a script
+	// asking for another format without a range must not end up in it
+	if !ranged && opts.format != render.Table {
+		return fmt.Errorf("--output %s needs --range or --start/--end; the interactive menu only shows tables", opts.format)
+	}
+	if opts.team, err = resolveTeam(ctx, src, userID, flagUsers); err != nil {
+		return err
+	}
+	if opts.filters, err = resolveFilter(ctx, src, opts); err != nil {
+		return err
+	}
+	if ranged {
+		label, start, end, unit, err := computeRangeFromFlags(flagRange, flagStart, flagEnd)
+		if err != nil {
+			return err
 		}
+		opts.period = unit
+		ctx, stop := interruptible(ctx)
+		defer stop()
+		return runRange(ctx, src, userID, label, start, end, opts)
+	}
 
-		// Interactive menu
-		return runMenu()
-	},
+	return runMenu(ctx, src, userID, opts)
 }
 
 func Execute() {
 	// Subcommands
 	rootCmd.AddCommand(loginCmd)
 	rootCmd.AddCommand(logoutCmd)
+	rootCmd.AddCommand(cacheCmd)
+	rootCmd.AddCommand(projectCmd)
+	rootCmd.AddCommand(budgetCmd)
+	rootCmd.AddCommand(utilizationCmd)
 
 	// Root flags (central, before Execute)
-	rootCmd.Flags().StringVarP(&flagRange, "range", "r", "", "predefined range: week|2w|month|3m|6m|ytd|all")
+	rootCmd.Flags().StringVarP(&flagRange, "range", "r", "", "range: week|2w|month|3m|6m|ytd|all, this-week, last-month, this-quarter, q2-2025, 2025-07, 2025-W14, 30d, yesterday, since monday, 3 weeks ago")
 	rootCmd.Flags().StringVarP(&flagStart, "start", "s", "", "start date (YYYY-MM-DD)")
 	rootCmd.Flags().StringVarP(&flagEnd, "end", "e", "", "end date (YYYY-MM-DD)")
+	rootCmd.Flags().StringVarP(&flagOutput, "output", "o", "table", "output format: table|json|csv|tsv|markdown|html")
+	rootCmd.Flags().StringVarP(&flagGroupBy, "group-by", "g", "project", "group hours by one or more (comma separated, outermost first) of: client|project|task|user|day|week|month|billable|tag")
+	rootCmd.Flags().StringVar(&flagBucket, "bucket", "", "time series: hours per --group-by row per day|week|month")
+	rootCmd.Flags().StringVar(&flagPivot, "pivot", "", "cross tab: spread the --group-by rows over columns of this dimension, e.g. project")
+	rootCmd.Flags().StringVar(&flagUsers, "users", "", "report on other users too: all|<id,...>|<email,...> (needs admin rights)")
+	rootCmd.Flags().BoolVar(&flagBillableOnly, "billable-only", false, "only count billable time")
+	rootCmd.Flags().BoolVar(&flagNonBillableOnly, "non-billable-only", false, "only count non-billable time")
+	rootCmd.Flags().BoolVar(&flagAmounts, "amounts", false, "add amounts (billable hours x hourly rate) per currency")
+	rootCmd.Flags().StringArrayVar(&flagProjects, "project", nil, "only count these projects: ID, name, glob (web*) or /regex/; repeatable")
+	rootCmd.Flags().StringArrayVar(&flagClients, "client", nil, "only count projects of these clients: ID, name, glob or /regex/; repeatable")
+	rootCmd.Flags().StringArrayVar(&flagTasks, "task", nil, "only count these tasks: ID, name, glob or /regex/; repeatable")
+	rootCmd.Flags().StringVar(&flagSearch, "search", "", "only count entries whose description contains this text")
+	rootCmd.Flags().StringVar(&flagWhere, "where", "", `only count entries matching this expression, e.g. 'client ~ "acme*" and billable and hours > 0.5'`)
+	rootCmd.Flags().StringVar(&flagCompare, "compare", "", "compare with the previous period (e.g. the month before a month), or YYYY-MM-DD:YYYY-MM-DD")
+	rootCmd.Flags().BoolVar(&flagNested, "nested", false, "with --group-by client, list each client's projects under it (same as --group-by client,project)")
+	rootCmd.PersistentFlags().BoolVar(&flagNoCache, "no-cache", false, "bypass the local cache and fetch everything from Paymo")
+	rootCmd.PersistentFlags().BoolVar(&flagOffline, "offline", false, "report from locally cached data only (no API key or network needed)")
+
+	// Global flags (apply to subcommands too)
+	rootCmd.PersistentFlags().StringVar(&flagAPIURL, "api-url", "", "Paymo API root URL, e.g. a local mock server (env PAYMOSTATS_API_URL)")
+	rootCmd.PersistentFlags().DurationVar(&flagTimeout, "timeout", 30*time.Second, "timeout per HTTP request (0 disables)")
+	rootCmd.PersistentFlags().StringVar(&flagProxy, "proxy", "", "HTTP(S) proxy URL (defaults to HTTPS_PROXY/HTTP_PROXY from the environment)")
+	rootCmd.PersistentFlags().StringVar(&flagRecord, "record", "", "record sanitized HTTP interactions to a cassette file")
+	rootCmd.PersistentFlags().StringVar(&flagReplay, "replay", "", "serve HTTP responses from a recorded cassette file instead of Paymo")
+	rootCmd.PersistentFlags().BoolVar(&flagDebug, "debug", false, "log HTTP requests (credentials redacted) with status, timing and size")
+	rootCmd.PersistentFlags().BoolVar(&flagTrace, "trace", false, "like --debug, plus headers and response bodies")
+	rootCmd.PersistentFlags().StringVar(&flagDebugFile, "debug-file", "", "write --debug/--trace output to this file instead of stderr")
+
+	// Root context is canceled on SIGTERM; Ctrl-C is only trapped around fetches
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+	defer stop()
 
-	if err := rootCmd.Execute(); err != nil {
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		// Only unexpected errors reach here
-		fmt.Fprintln(os.Stderr, "Error:", err)
+		printError(err)
 	}
 }
@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestRunReportScriptedWithoutRange checks formats other than the table
+// need a range instead of falling back to the interactive menu
+func TestRunReportScriptedWithoutRange(t *testing.T) {
+	output, groupBy := flagOutput, flagGroupBy
+	t.Cleanup(func() { flagOutput, flagGroupBy = output, groupBy })
+	flagGroupBy = "project"
+
+	for _, format := range []string{"json", "csv", "tsv", "markdown", "html"} {
+		t.Run(format, func(t *testing.T) {
+			flagOutput = format
+			err := runReport(context.Background(), nil, 1)
+			if err == nil || !strings.Contains(err.Error(), "--range") {
+				t.Errorf("got error %v, want one asking for --range", err)
+			}
+			if !scripted() {
+				t.Error("scripted() = false, the login prompts would wait for input")
+			}
+		})
+	}
+}
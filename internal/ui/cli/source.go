@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+	"github.com/Ma-Kas/paymostats/internal/cache"
+	"github.com/Ma-Kas/paymostats/internal/config"
+)
+
+var (
+	// flags for the local cache
+	flagNoCache bool
+	flagOffline bool
+)
+
+// dataSource is where reports get their data from: Paymo directly
+// (*api.Client) or the local cache in front of it (*cache.Source)
+type dataSource interface {
+	Entries(ctx context.Context, userID int, start, end time.Time) ([]api.TimeEntry, error)
+	Projects(ctx context.Context) (map[int]api.Project, error)
+	Clients(ctx context.Context) (map[int]api.Customer, error)
+	Tasks(ctx context.Context) (map[int]api.Task, error)
+	TaskLists(ctx context.Context) (map[int]api.TaskList, error)
+	Users(ctx context.Context) (map[int]api.User, error)
+}
+
+// staleSource is implemented by sources serving possibly old data (offline
+// mode), so reports can say how old and where the data ends
+type staleSource interface {
+	AsOf() time.Time
+	Until() time.Time
+}
+
+// dataEnd returns end, or where src's data stopped if that's earlier, so
+// printed ranges match the entries behind them. Call it after fetching
+func dataEnd(src dataSource, end time.Time) time.Time {
+	if s, ok := src.(staleSource); ok && !s.Until().IsZero() && s.Until().Before(end) {
+		return s.Until()
+	}
+	return end
+}
+
+// newSource puts the local cache in front of client unless it's disabled.
+// Recording and replaying bypass it, so the cassette sees every request
+func newSource(client *api.Client, userID int) dataSource {
+	if flagNoCache || flagRecord != "" || flagReplay != "" {
+		return client
+	}
+	store, err := cache.Open(config.ResolveApiURL(flagAPIURL))
+	if err != nil {
+		// No usable cache dir; reports still work, just uncached
+		return client
+	}
+	// Remember who is logged in, for --offline
+	_ = store.SaveMe(userID)
+	return cache.NewSource(client, store, userID)
+}
+
+// newOfflineSource serves reports from the cache only, for the user last seen online
+func newOfflineSource() (dataSource, int, error) {
+	if flagNoCache {
+		return nil, 0, fmt.Errorf("--offline and --no-cache can't be combined")
+	}
+	store, err := cache.Open(config.ResolveApiURL(flagAPIURL))
+	if err != nil {
+		return nil, 0, err
+	}
+	return cache.NewOffline(store)
+}
+
+// connect sets up the data source for subcommands: the offline cache with
+// --offline, otherwise the stored API key (no interactive login prompts)
+func connect(ctx context.Context) (dataSource, int, error) {
+	if flagOffline {
+		return newOfflineSource()
+	}
+	apiKey, err := resolveApiKey()
+	if err == config.ErrNoApiKey {
+		return nil, 0, fmt.Errorf("no API key found, run `paymostats login` first")
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	client := newClient(apiKey)
+	userID, err := client.Me(ctx)
+	if err != nil {
+		if errors.Is(err, api.ErrUnauthorized) {
+			return nil, 0, fmt.Errorf("stored API key is invalid or expired, run `paymostats login` again")
+		}
+		return nil, 0, fmt.Errorf("failed to get user: %w", err)
+	}
+	return newSource(client, userID), userID, nil
+}
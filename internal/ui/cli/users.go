@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Ma-Kas/paymostats/internal/api"
+)
+
+// resolveTeam turns --users into the users to report on: "all", or a comma
+// separated list of user IDs, emails or "me". An empty spec means just the
+// caller and returns nil
+func resolveTeam(ctx context.Context, src dataSource, userID int, spec string) ([]api.User, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	users, err := src.Users(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+
+	var team []api.User
+	if strings.EqualFold(spec, "all") {
+		for _, u := range users {
+			team = append(team, u)
+		}
+		sort.Slice(team, func(i, j int) bool { return team[i].ID < team[j].ID })
+		return team, nil
+	}
+
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(spec, ",") {
+		u, err := findUser(users, userID, strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		if !seen[u.ID] {
+			seen[u.ID] = true
+			team = append(team, u)
+		}
+	}
+	return team, nil
+}
+
+// findUser resolves a user by ID, email (case insensitive) or "me"
+func findUser(users map[int]api.User, userID int, query string) (api.User, error) {
+	if strings.EqualFold(query, "me") {
+		query = strconv.Itoa(userID)
+	}
+	if id, err := strconv.Atoi(query); err == nil {
+		if u, ok := users[id]; ok {
+			return u, nil
+		}
+		return api.User{}, fmt.Errorf("no user with ID %d", id)
+	}
+	for _, u := range users {
+		if strings.EqualFold(u.Email, query) {
+			return u, nil
+		}
+	}
+	return api.User{}, fmt.Errorf("no user matches %q (use IDs or emails)", query)
+}
+
+// teamLabel notes a team report in the range label
+func teamLabel(label string, team []api.User) string {
+	switch len(team) {
+	case 0:
+		return label
+	case 1:
+		return label + " - " + userName(team[0])
+	default:
+		return fmt.Sprintf("%s - %d users", label, len(team))
+	}
+}
+
+func teamMap(team []api.User) map[int]api.User {
+	if team == nil {
+		return nil
+	}
+	m := make(map[int]api.User, len(team))
+	for _, u := range team {
+		m[u.ID] = u
+	}
+	return m
+}
+
+func userName(u api.User) string {
+	switch {
+	case u.Name != "":
+		return u.Name
+	case u.Email != "":
+		return u.Email
+	default:
+		return fmt.Sprintf("user %d", u.ID)
+	}
+}
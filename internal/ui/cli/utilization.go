@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Ma-Kas/paymostats/internal/config"
+	"github.com/Ma-Kas/paymostats/internal/render"
+	"github.com/Ma-Kas/paymostats/internal/report"
+)
+
+var (
+	// utilization flags, overriding the config file
+	flagTarget      float64 // hours per week
+	flagDailyTarget float64 // hours per workday
+)
+
+var utilizationCmd = &cobra.Command{
+	Use:   "utilization [flags]",
+	Short: "Compare the hours you logged with your working-hours target, week by week",
+	Long: `Show the hours expected and logged per week, the utilization (logged as a share
+of expected) and the running overtime/undertime balance over the range.
+
+The target, workdays and holidays come from the config file (see below) and
+--target/--daily-target override it. Without range flags, this month is shown.
+
+Config file (` + configPathHelp() + `):
+
+  {
+    "utilization": {
+      "weekly_hours": 32,
+      "workdays": ["mon", "tue", "wed", "thu", "fri"],
+      "holidays": ["2025-04-18", "12-25", "12-26"],
+      "billable_only": true
+    }
+  }
+
+daily_hours sets the target per workday instead of per week. Holidays are
+YYYY-MM-DD, or MM-DD for every year.`,
+	Example: `  paymostats utilization --target 32
+  paymostats utilization --range 3m --billable-only
+  paymostats utilization --range ytd -o csv`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := render.ParseFormat(flagOutput)
+		if err != nil {
+			return err
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("load config: %w", err)
+		}
+		settings := cfg.Utilization
+		if cmd.Flags().Changed("target") {
+			settings.WeeklyHours, settings.DailyHours = flagTarget, 0
+		}
+		if cmd.Flags().Changed("daily-target") {
+			settings.DailyHours = flagDailyTarget
+		}
+		if cmd.Flags().Changed("billable-only") {
+			settings.BillableOnly = flagBillableOnly
+		}
+		cal, err := calendarFrom(settings)
+		if err != nil {
+			return err
+		}
+
+		label, start, end, err := utilizationRange(flagRange, flagStart, flagEnd)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := interruptible(cmd.Context())
+		defer stop()
+
+		src, userID, err := connect(ctx)
+		if err != nil {
+			return err
+		}
+		opts := reportOptions{format: format}
+		if settings.BillableOnly {
+			billable := true
+			opts.billable = &billable
+		}
+		entries, err := fetchEntries(ctx, src, userID, start, end, opts)
+		if err != nil {
+			return err
+		}
+		end = dataEnd(src, end)
+		if len(entries) > 0 && opts.billable != nil {
+			lk, err := lookupFor(ctx, src, opts)
+			if err != nil {
+				return err
+			}
+			entries = filterBillable(entries, lk, opts)
+		}
+		// All time starts at the first entry rather than 1970
+		if start.Unix() == 0 {
+			start = end
+			for _, e := range entries {
+				if t := e.Time(); !t.IsZero() && t.Before(start) {
+					start = t
+				}
+			}
+		}
+
+		u := report.BuildUtilization(entries, cal, start, end)
+		u.Label, u.BillableOnly = "Utilization - "+label, settings.BillableOnly
+		if s, ok := src.(staleSource); ok {
+			u.AsOf = s.AsOf()
+		}
+		return render.RenderUtilization(os.Stdout, format, u)
+	},
+}
+
+// utilizationRange resolves the range flags like computeRangeFromFlags,
+// defaulting to this month. The calendar expects whole days, so the range
+// starts at midnight, else a rolling range would leave out the logged hours
+// of its first morning while still expecting them
+func utilizationRange(rng, startStr, endStr string) (string, time.Time, time.Time, error) {
+	if rng == "" && startStr == "" && endStr == "" {
+		rng = "this-month"
+	}
+	label, start, end, _, err := computeRangeFromFlags(rng, startStr, endStr)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, err
+	}
+	return label, periodStart("day", start), end, nil
+}
+
+// calendarFrom validates the utilization settings and turns them into a calendar
+func calendarFrom(u config.Utilization) (report.Calendar, error) {
+	var cal report.Calendar
+	if u.WeeklyHours < 0 || u.DailyHours < 0 {
+		return cal, fmt.Errorf("working-hours targets can't be negative")
+	}
+	if u.WeeklyHours == 0 && u.DailyHours == 0 {
+		return cal, fmt.Errorf("no working-hours target set, pass --target or set weekly_hours in %s", configPathHelp())
+	}
+
+	workdays := u.Workdays
+	if len(workdays) == 0 {
+		workdays = []string{"mon", "tue", "wed", "thu", "fri"}
+	}
+	n := 0
+	for _, name := range workdays {
+		d, ok := weekdays[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return cal, fmt.Errorf("unknown workday %q (use e.g. mon or Monday)", name)
+		}
+		if !cal.Workdays[d] {
+			cal.Workdays[d] = true
+			n++
+		}
+	}
+
+	cal.DailyHours = u.DailyHours
+	if cal.DailyHours == 0 {
+		cal.DailyHours = u.WeeklyHours / float64(n)
+	}
+
+	cal.Holidays = make(map[string]bool, len(u.Holidays))
+	for _, h := range u.Holidays {
+		h = strings.TrimSpace(h)
+		if _, err := time.Parse("2006-01-02", h); err != nil {
+			if _, err := time.Parse("01-02", h); err != nil {
+				return cal, fmt.Errorf("invalid holiday %q, use YYYY-MM-DD or MM-DD", h)
+			}
+		}
+		cal.Holidays[h] = true
+	}
+	return cal, nil
+}
+
+// configPathHelp is the config file path for help texts
+func configPathHelp() string {
+	path, err := config.Path()
+	if err != nil {
+		return "paymostats/config.json in your user config dir"
+	}
+	return path
+}
+
+func init() {
+	utilizationCmd.Flags().StringVarP(&flagRange, "range", "r", "", "range, e.g. month|3m|ytd, last-month, this-quarter, 2025-07 (default this-month)")
+	utilizationCmd.Flags().StringVarP(&flagStart, "start", "s", "", "start date (YYYY-MM-DD)")
+	utilizationCmd.Flags().StringVarP(&flagEnd, "end", "e", "", "end date (YYYY-MM-DD)")
+	utilizationCmd.Flags().StringVarP(&flagOutput, "output", "o", "table", "output format: table|json|csv|tsv|markdown|html")
+	utilizationCmd.Flags().Float64Var(&flagTarget, "target", 0, "target hours per week, spread over the workdays")
+	utilizationCmd.Flags().Float64Var(&flagDailyTarget, "daily-target", 0, "target hours per workday (instead of --target)")
+	utilizationCmd.Flags().BoolVar(&flagBillableOnly, "billable-only", false, "only count billable hours towards the target")
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ma-Kas/paymostats/internal/config"
+)
+
+// TestUtilizationRange checks the range starts on a whole day, so the
+// calendar doesn't expect hours from before the entries were fetched
+func TestUtilizationRange(t *testing.T) {
+	now := time.Now().UTC()
+	today := periodStart("day", now)
+	tests := []struct {
+		name            string
+		rng, start, end string
+		wantStart       time.Time
+		wantEndAtLatest time.Time
+	}{
+		{name: "default", wantStart: periodStart("month", now), wantEndAtLatest: now.Add(time.Minute)},
+		{name: "rolling month", rng: "month", wantStart: today.AddDate(0, -1, 0), wantEndAtLatest: now.Add(time.Minute)},
+		{name: "rolling days", rng: "10d", wantStart: today.AddDate(0, 0, -10), wantEndAtLatest: now.Add(time.Minute)},
+		{name: "dates", start: "2026-03-02", end: "2026-03-06",
+			wantStart: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), wantEndAtLatest: time.Date(2026, 3, 6, 23, 59, 59, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, start, end, err := utilizationRange(tt.rng, tt.start, tt.end)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !start.Equal(tt.wantStart) {
+				t.Errorf("start %s, want %s", start, tt.wantStart)
+			}
+			if end.After(tt.wantEndAtLatest) {
+				t.Errorf("end %s, want %s at the latest", end, tt.wantEndAtLatest)
+			}
+		})
+	}
+}
+
+func TestCalendarFrom(t *testing.T) {
+	tests := []struct {
+		name       string
+		settings   config.Utilization
+		wantDaily  float64
+		wantWeekly float64
+		off        []string // workdays expecting no hours
+		wantErr    bool
+	}{
+		{name: "weekly target over five days", settings: config.Utilization{WeeklyHours: 40}, wantDaily: 8, wantWeekly: 40},
+		{name: "weekly target over four days", settings: config.Utilization{WeeklyHours: 32, Workdays: []string{"Mon", "tue", "wed", "Thursday"}}, wantDaily: 8, wantWeekly: 32, off: []string{"2026-12-25"}},
+		{name: "daily target", settings: config.Utilization{DailyHours: 6}, wantDaily: 6, wantWeekly: 30},
+		{name: "daily target wins", settings: config.Utilization{WeeklyHours: 40, DailyHours: 6, Workdays: []string{"mon", "wed"}}, wantDaily: 6, wantWeekly: 12},
+		{name: "repeated workdays count once", settings: config.Utilization{WeeklyHours: 16, Workdays: []string{"mon", "monday", "fri"}}, wantDaily: 8, wantWeekly: 16},
+		{name: "holidays by date and every year", settings: config.Utilization{WeeklyHours: 40, Holidays: []string{"2026-12-24", " 12-25 "}}, wantDaily: 8, wantWeekly: 40, off: []string{"2026-12-24", "2026-12-25", "2025-12-25"}},
+		{name: "no target", settings: config.Utilization{}, wantErr: true},
+		{name: "negative target", settings: config.Utilization{DailyHours: -1}, wantErr: true},
+		{name: "unknown workday", settings: config.Utilization{WeeklyHours: 40, Workdays: []string{"someday"}}, wantErr: true},
+		{name: "invalid holiday", settings: config.Utilization{WeeklyHours: 40, Holidays: []string{"24.12."}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cal, err := calendarFrom(tt.settings)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if cal.DailyHours != tt.wantDaily || cal.WeeklyHours() != tt.wantWeekly {
+				t.Errorf("%.1f hours a day, %.1f a week; want %.1f and %.1f", cal.DailyHours, cal.WeeklyHours(), tt.wantDaily, tt.wantWeekly)
+			}
+			for _, day := range tt.off {
+				d, err := time.Parse("2006-01-02", day)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := cal.Expected(d); got != 0 {
+					t.Errorf("%s expects %.1f hours, want none", day, got)
+				}
+			}
+		})
+	}
+}